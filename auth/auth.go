@@ -0,0 +1,141 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// TokenTTL — срок действия токена, выданного при входе
+const TokenTTL = 8 * time.Hour
+
+// Роли пользователей: администратор видит задачи всех пользователей
+const (
+	RoleUser  = "user"
+	RoleAdmin = "admin"
+)
+
+// Области действия персональных токенов API: read — только чтение, write — чтение и
+// изменение задач и списков, admin — все права пользователя, включая управление токенами
+// и роль администратора
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+	ScopeAdmin = "admin"
+)
+
+// APITokenPrefix отличает персональные токены API от токенов, выданных при входе
+const APITokenPrefix = "todo_"
+
+// ErrInvalidToken возвращается для подписанного другим ключом или просроченного токена
+var ErrInvalidToken = errors.New("недействительный токен")
+
+// User — пользователь, от имени которого выполняется запрос.
+// ID 0 — вход по общему паролю TODO_PASSWORD или работа без аутентификации.
+type User struct {
+	ID   int
+	Role string
+	// Scope — область действия персонального токена API, пустая строка — вход по паролю
+	Scope string
+}
+
+// IsAdmin проверяет, что пользователь — администратор
+func (u User) IsAdmin() bool {
+	return u.Role == RoleAdmin
+}
+
+// CanAccess проверяет, может ли пользователь работать с задачей владельца ownerID
+func (u User) CanAccess(ownerID int) bool {
+	return u.IsAdmin() || u.ID == ownerID
+}
+
+// Claims — содержимое токена. Hash — отпечаток пароля, с которым выполнен вход:
+// после смены пароля выданные ранее токены перестают действовать.
+type Claims struct {
+	UserID int    `json:"uid,omitempty"`
+	Role   string `json:"role"`
+	Hash   string `json:"hash"`
+	jwt.RegisteredClaims
+}
+
+// PasswordHash возвращает SHA-256 строки в шестнадцатеричном виде
+func PasswordHash(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// HashPassword хэширует пароль пользователя для хранения в базе
+func HashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hash), nil
+}
+
+// CheckPassword сравнивает пароль с хэшем из базы
+func CheckPassword(hash, password string) bool {
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil
+}
+
+// NewToken выдаёт JWT пользователю user со сроком действия TokenTTL.
+// Токен подписывается секретом сервера, hash — отпечаток пароля пользователя.
+func NewToken(secret string, user User, hash string, now time.Time) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, Claims{
+		UserID: user.ID,
+		Role:   user.Role,
+		Hash:   hash,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
+		},
+	})
+	return token.SignedString([]byte(secret))
+}
+
+// ParseToken проверяет подпись и срок действия токена и возвращает его содержимое
+func ParseToken(secret, tokenString string) (Claims, error) {
+	var c Claims
+	_, err := jwt.ParseWithClaims(tokenString, &c, func(*jwt.Token) (interface{}, error) {
+		return []byte(secret), nil
+	}, jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}), jwt.WithExpirationRequired())
+	if err != nil {
+		return Claims{}, ErrInvalidToken
+	}
+	return c, nil
+}
+
+// NewAPIToken создаёт случайный персональный токен API и возвращает его вместе с хэшем
+// для хранения в базе: сам токен показывается пользователю только один раз
+func NewAPIToken() (string, string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", err
+	}
+	token := APITokenPrefix + hex.EncodeToString(buf)
+	return token, APITokenHash(token), nil
+}
+
+// APITokenHash возвращает хэш персонального токена API, по которому он ищется в базе
+func APITokenHash(token string) string {
+	return PasswordHash(token)
+}
+
+type contextKey struct{}
+
+// WithUser сохраняет пользователя запроса в контексте
+func WithUser(ctx context.Context, user User) context.Context {
+	return context.WithValue(ctx, contextKey{}, user)
+}
+
+// FromContext возвращает пользователя запроса; без него — пользователя без прав
+func FromContext(ctx context.Context) User {
+	user, _ := ctx.Value(contextKey{}).(User)
+	return user
+}
@@ -0,0 +1,203 @@
+package calendar
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Calendar — производственный календарь: выходные дни недели, праздники
+// и перенесённые рабочие дни, выпадающие на выходные
+type Calendar struct {
+	holidays map[string]bool
+	workdays map[string]bool
+	// years — годы, за которые в календаре есть праздники или перенесённые рабочие дни
+	years map[int]bool
+}
+
+// calendarFile — формат JSON-файла календаря, даты в формате YYYYMMDD
+type calendarFile struct {
+	Holidays []string `json:"holidays"`
+	Workdays []string `json:"workdays"`
+}
+
+var (
+	mu         sync.RWMutex
+	defaultCal = Russian()
+)
+
+// Default возвращает календарь, по которому вычисляются правила с рабочими днями
+func Default() *Calendar {
+	mu.RLock()
+	defer mu.RUnlock()
+	return defaultCal
+}
+
+// SetDefault заменяет календарь по умолчанию
+func SetDefault(cal *Calendar) {
+	mu.Lock()
+	defer mu.Unlock()
+	defaultCal = cal
+}
+
+// New создаёт календарь из списков праздников и перенесённых рабочих дней
+func New(holidays, workdays []time.Time) *Calendar {
+	cal := &Calendar{
+		holidays: make(map[string]bool),
+		workdays: make(map[string]bool),
+		years:    make(map[int]bool),
+	}
+	for _, day := range holidays {
+		cal.holidays[day.Format("20060102")] = true
+		cal.years[day.Year()] = true
+	}
+	for _, day := range workdays {
+		cal.workdays[day.Format("20060102")] = true
+		cal.years[day.Year()] = true
+	}
+	return cal
+}
+
+// Covers проверяет, есть ли в календаре данные за год даты. Для других лет
+// праздники неизвестны, и рабочие дни по календарю вычислить нельзя.
+// Календарь без дат годы не ограничивает.
+func (c *Calendar) Covers(t time.Time) bool {
+	return len(c.years) == 0 || c.years[t.Year()]
+}
+
+// IsWorkday проверяет, является ли день рабочим
+func (c *Calendar) IsWorkday(t time.Time) bool {
+	key := t.Format("20060102")
+	if c.workdays[key] {
+		return true
+	}
+	if c.holidays[key] {
+		return false
+	}
+	return t.Weekday() != time.Saturday && t.Weekday() != time.Sunday
+}
+
+// AddWorkdays возвращает день, отстоящий от date на n рабочих дней вперёд
+func (c *Calendar) AddWorkdays(date time.Time, n int) time.Time {
+	for n > 0 {
+		date = date.AddDate(0, 0, 1)
+		if c.IsWorkday(date) {
+			n--
+		}
+	}
+	return date
+}
+
+// Load загружает календарь из файла: JSON со списками "holidays" и "workdays"
+// или ICS, события которого считаются праздниками
+func Load(path string) (*Calendar, error) {
+	if strings.EqualFold(filepath.Ext(path), ".ics") {
+		return LoadICS(path)
+	}
+	return LoadJSON(path)
+}
+
+// LoadJSON загружает календарь из JSON-файла
+func LoadJSON(path string) (*Calendar, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении календаря: %w", err)
+	}
+
+	var file calendarFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("ошибка при разборе календаря: %w", err)
+	}
+
+	holidays, err := parseDates(file.Holidays)
+	if err != nil {
+		return nil, err
+	}
+	workdays, err := parseDates(file.Workdays)
+	if err != nil {
+		return nil, err
+	}
+	return New(holidays, workdays), nil
+}
+
+// LoadICS загружает праздники из файла iCalendar: каждый день события VEVENT
+// от DTSTART до DTEND (не включая его) считается выходным
+func LoadICS(path string) (*Calendar, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении календаря: %w", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		// Строки, начинающиеся с пробела или табуляции, продолжают предыдущую
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении календаря: %w", err)
+	}
+
+	var holidays []time.Time
+	var start, end time.Time
+	inEvent := false
+	for _, line := range lines {
+		name, value, ok := strings.Cut(line, ":")
+		if !ok {
+			continue
+		}
+		// Параметры свойства (;VALUE=DATE, ;TZID=...) не влияют на дату
+		name, _, _ = strings.Cut(strings.ToUpper(name), ";")
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent = true
+			start, end = time.Time{}, time.Time{}
+		case name == "END" && value == "VEVENT":
+			inEvent = false
+			if start.IsZero() {
+				return nil, fmt.Errorf("в событии календаря не указан DTSTART")
+			}
+			if end.IsZero() || !end.After(start) {
+				end = start.AddDate(0, 0, 1)
+			}
+			for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+				holidays = append(holidays, day)
+			}
+		case inEvent && (name == "DTSTART" || name == "DTEND"):
+			datePart, _, _ := strings.Cut(value, "T")
+			date, err := time.Parse("20060102", datePart)
+			if err != nil {
+				return nil, fmt.Errorf("неверная дата в календаре: %s", value)
+			}
+			if name == "DTSTART" {
+				start = date
+			} else {
+				end = date
+			}
+		}
+	}
+	return New(holidays, nil), nil
+}
+
+func parseDates(values []string) ([]time.Time, error) {
+	var dates []time.Time
+	for _, value := range values {
+		date, err := time.Parse("20060102", value)
+		if err != nil {
+			return nil, fmt.Errorf("неверная дата в календаре: %s", value)
+		}
+		dates = append(dates, date)
+	}
+	return dates, nil
+}
@@ -0,0 +1,35 @@
+package calendar
+
+// Нерабочие праздничные дни и перенесённые выходные, выпадающие на будни,
+// по постановлениям Правительства РФ о переносе выходных дней
+var russianHolidays = []string{
+	// 2024
+	"20240101", "20240102", "20240103", "20240104", "20240105", "20240108",
+	"20240223", "20240308", "20240429", "20240430", "20240501", "20240509", "20240510",
+	"20240612", "20241104", "20241230", "20241231",
+	// 2025
+	"20250101", "20250102", "20250103", "20250106", "20250107", "20250108",
+	"20250501", "20250502", "20250508", "20250509", "20250612", "20250613",
+	"20251103", "20251104", "20251231",
+	// 2026
+	"20260101", "20260102", "20260105", "20260106", "20260107", "20260108", "20260109",
+	"20260223", "20260309", "20260501", "20260511", "20260612", "20261104", "20261231",
+	// 2027 — праздники по статье 112 Трудового кодекса с переносом выпавших на выходные;
+	// переносы выходных 2 и 3 января задаются постановлением и добавляются файлом TODO_HOLIDAYS
+	"20270101", "20270104", "20270105", "20270106", "20270107", "20270108",
+	"20270223", "20270308", "20270503", "20270510", "20270614", "20271104",
+}
+
+// Субботы, ставшие рабочими днями из-за переноса выходных
+var russianWorkdays = []string{
+	"20240427", "20241102", "20241228",
+	"20251101",
+}
+
+// Russian возвращает встроенный производственный календарь России на 2024–2027 годы.
+// Для других лет правила с рабочими днями возвращают ошибку, см. Calendar.Covers.
+func Russian() *Calendar {
+	holidays, _ := parseDates(russianHolidays)
+	workdays, _ := parseDates(russianWorkdays)
+	return New(holidays, workdays)
+}
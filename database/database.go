@@ -11,7 +11,14 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func CreateOrGetDb() (*sql.DB, error) {
+// Open открывает базу PostgreSQL по адресу из TODO_DB_URL, а без него — файл SQLite
+// из TODO_DBFILE (по умолчанию scheduler.db), не изменяя схему базы
+func Open() (*DB, error) {
+	if url := os.Getenv("TODO_DB_URL"); url != "" {
+		log.Println("Подключение к базе данных PostgreSQL.")
+		return OpenPostgres(url)
+	}
+
 	dbFile := os.Getenv("TODO_DBFILE")
 	if dbFile == "" {
 		dbFile = "scheduler.db"
@@ -21,9 +28,6 @@ func CreateOrGetDb() (*sql.DB, error) {
 	_, err := os.Stat(dbFile)
 	if os.IsNotExist(err) {
 		log.Println("Файл базы данных не существует. Создание нового файла базы данных.")
-		if err := createDB(dbFile); err != nil {
-			return nil, err
-		}
 	} else if err != nil {
 		log.Printf("Ошибка при проверке файла базы данных: %v", err)
 		return nil, err
@@ -31,36 +35,53 @@ func CreateOrGetDb() (*sql.DB, error) {
 		log.Println("База данных существует.")
 	}
 
-	// Открываем базу данных
+	return OpenSQLite(dbFile)
+}
+
+// OpenSQLite открывает файл базы SQLite, создавая его при необходимости
+func OpenSQLite(dbFile string) (*DB, error) {
 	db, err := sql.Open("sqlite3", dbFile)
 	if err != nil {
 		return nil, err
 	}
-
-	return db, nil
+	return &DB{DB: db}, nil
 }
 
-func createDB(dbFile string) error {
-	db, err := sql.Open("sqlite3", dbFile)
+// OpenPostgres подключается к базе PostgreSQL по адресу url
+func OpenPostgres(url string) (*DB, error) {
+	db, err := sql.Open("postgres", url)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("ошибка при подключении к PostgreSQL: %w", err)
 	}
-	defer db.Close()
-	_, err = db.Exec(`
-		CREATE TABLE IF NOT EXISTS scheduler (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			date TEXT NOT NULL DEFAULT "",
-			title TEXT NOT NULL DEFAULT "",
-			comment TEXT NOT NULL DEFAULT "",
-			repeat VARCHAR(128) NOT NULL DEFAULT ""
-		);
-		CREATE INDEX IF NOT EXISTS idx_date ON scheduler (date);
-	`)
+	return &DB{DB: db, dialect: dialect{postgres: true}}, nil
+}
+
+// CreateOrGetDb открывает базу данных и подготавливает её к работе в Setup
+func CreateOrGetDb() (*DB, error) {
+	db, err := Open()
 	if err != nil {
-		log.Println(err)
+		return nil, err
+	}
+	if err := Setup(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Setup обновляет схему базы до последней версии и настраивает полнотекстовый поиск задач в SQLite
+func Setup(db *DB) error {
+	if err := MigrateTo(db, LatestVersion()); err != nil {
 		return err
 	}
-	return nil
+	if db.Postgres() {
+		return nil
+	}
+	return setupSearch(db)
 }
 
 type Task struct {
@@ -69,39 +90,80 @@ type Task struct {
 	Title   string
 	Comment string
 	Repeat  string
+	// RepeatUntil — последняя допустимая дата повторения, нулевое значение — без ограничения
+	RepeatUntil time.Time
+	// RepeatCount — общее количество выполнений задачи, 0 — без ограничения
+	RepeatCount int
+	// DoneCount — сколько раз задача уже отмечена выполненной
+	DoneCount int
+	// Time — время выполнения в формате HH:MM, пустая строка — в течение дня
+	Time string
+	// Timezone — часовой пояс IANA, пустая строка — пояс сервера по умолчанию
+	Timezone string
+	// RepeatMode — от чего отсчитывается следующая дата: "due" — от даты задачи,
+	// "completion" — от даты выполнения
+	RepeatMode string
+	// OwnerID — идентификатор пользователя-владельца, 0 — задача без владельца
+	OwnerID int
+	// ListID — общий список, в который входит задача, 0 — личная задача
+	ListID int
+	// AssigneeID — исполнитель задачи, 0 — не назначен
+	AssigneeID int
+	// DeletedAt — время перемещения в корзину, ArchivedAt — в архив после выполнения;
+	// нулевое значение — задача активна
+	DeletedAt  time.Time
+	ArchivedAt time.Time
+}
+
+// formatOptionalDate возвращает дату в формате YYYYMMDD или пустую строку для нулевой даты
+func formatOptionalDate(date time.Time) string {
+	if date.IsZero() {
+		return ""
+	}
+	return date.Format("20060102")
 }
 
 // InsertTask вставляет новую задачу в базу данных и возвращает её идентификатор
-func InsertTask(db *sql.DB, date time.Time, title, comment, repeat string) (int, error) {
+func InsertTask(db *DB, task Task) (int, error) {
 	// Подготовка SQL-запроса для вставки задачи
 	query := `
-INSERT INTO scheduler (date, title, comment, repeat)
-VALUES (?, ?, ?, ?)
+INSERT INTO scheduler (date, title, comment, repeat, repeat_until, repeat_count, due_time, timezone, repeat_mode,
+	owner_id, list_id, assignee_id)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id
 	`
 
-	// Выполнение запроса
-	result, err := db.Exec(query, date.Format("20060102"), title, comment, repeat)
+	// Выполнение запроса и получение идентификатора созданной записи
+	var taskID int
+	err := db.QueryRow(query, task.Date.Format("20060102"), task.Title, task.Comment, task.Repeat,
+		formatOptionalDate(task.RepeatUntil), task.RepeatCount, task.Time, task.Timezone, task.RepeatMode, task.OwnerID,
+		task.ListID, task.AssigneeID).Scan(&taskID)
 	if err != nil {
 		return 0, fmt.Errorf("ошибка при вставке задачи: %w", err)
 	}
 
-	// Получение идентификатора созданной записи
-	taskID, err := result.LastInsertId()
-	if err != nil {
-		return 0, fmt.Errorf("ошибка при получении ID вставленной задачи: %w", err)
-	}
+	return taskID, nil
+}
 
-	return int(taskID), nil
+// activeTask — условие для задач, которые не перемещены в корзину или архив
+const activeTask = "deleted_at = '' AND archived_at = ''"
+
+// GetTaskByID возвращает задачу, если она не перемещена в корзину или архив
+func GetTaskByID(db *DB, id string) (*Task, error) {
+	return getTask(db, activeTask, id)
 }
 
-func GetTaskByID(db *sql.DB, id string) (*Task, error) {
+func getTask(db *DB, condition string, id interface{}) (*Task, error) {
 	var task Task
-	var dateString string
-	query := "SELECT id, date, title, comment, repeat FROM scheduler WHERE id = ?"
-	err := db.QueryRow(query, id).Scan(&task.ID, &dateString, &task.Title, &task.Comment, &task.Repeat)
+	var dateString, untilString, deletedAt, archivedAt string
+	query := `SELECT id, date, title, comment, repeat, repeat_until, repeat_count, done_count, due_time, timezone, repeat_mode,
+		owner_id, list_id, assignee_id, deleted_at, archived_at FROM scheduler WHERE id = ? AND ` + condition
+	err := db.QueryRow(query, id).Scan(&task.ID, &dateString, &task.Title, &task.Comment, &task.Repeat,
+		&untilString, &task.RepeatCount, &task.DoneCount, &task.Time, &task.Timezone, &task.RepeatMode, &task.OwnerID,
+		&task.ListID, &task.AssigneeID, &deletedAt, &archivedAt)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, fmt.Errorf("Задача не найдена")
+			return nil, ErrTaskNotFound
 		}
 		log.Println("Ошибка при выполнении запроса:", err)
 		return nil, fmt.Errorf("Ошибка при получении задачи")
@@ -112,17 +174,29 @@ func GetTaskByID(db *sql.DB, id string) (*Task, error) {
 	if err != nil {
 		return nil, fmt.Errorf("Ошибка при преобразовании даты")
 	}
+	if untilString != "" {
+		task.RepeatUntil, err = time.Parse("20060102", untilString)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при преобразовании даты")
+		}
+	}
+	task.DeletedAt = parseOptionalTime(deletedAt)
+	task.ArchivedAt = parseOptionalTime(archivedAt)
 
 	return &task, nil
 }
 
-func UpdateTask(db *sql.DB, id int, date time.Time, title, comment, repeat string) error {
+// UpdateTask обновляет задачу; счётчик выполнений при этом не меняется
+func UpdateTask(db *DB, task Task) error {
 	query := `
 		UPDATE scheduler 
-		SET date = ?, title = ?, comment = ?, repeat = ? 
-		WHERE id = ?`
+		SET date = ?, title = ?, comment = ?, repeat = ?, repeat_until = ?, repeat_count = ?, due_time = ?, timezone = ?,
+			repeat_mode = ?, list_id = ?, assignee_id = ?
+		WHERE id = ? AND ` + activeTask
 
-	result, err := db.Exec(query, date.Format("20060102"), title, comment, repeat, id)
+	result, err := db.Exec(query, task.Date.Format("20060102"), task.Title, task.Comment, task.Repeat,
+		formatOptionalDate(task.RepeatUntil), task.RepeatCount, task.Time, task.Timezone, task.RepeatMode,
+		task.ListID, task.AssigneeID, task.ID)
 	if err != nil {
 		return fmt.Errorf("ошибка при обновлении задачи: %w", err)
 	}
@@ -133,19 +207,63 @@ func UpdateTask(db *sql.DB, id int, date time.Time, title, comment, repeat strin
 	}
 
 	if rowsAffected == 0 {
-		return fmt.Errorf("Задача не найдена")
+		return ErrTaskNotFound
 	}
 
 	return nil
 }
 
-func UpdateTaskDate(db *sql.DB, taskID int, newDate time.Time) error {
-	query := `UPDATE scheduler SET date = ? WHERE id = ?`
-	_, err := db.Exec(query, newDate.Format("20060102"), taskID)
-	return err
+// CompleteTask записывает выполнение c в историю и в той же транзакции переносит задачу
+// с даты taskDate на nextDate с правилом repeat, увеличивая счётчик выполнений. Нулевая
+// nextDate перемещает задачу в архив. Задача меняется, только если её дата всё ещё taskDate:
+// иначе её уже перенёс другой запрос, и транзакция откатывается с ErrTaskChanged.
+// Возвращает идентификатор записи истории.
+func CompleteTask(db *DB, c Completion, taskDate, nextDate time.Time, repeat string) (int, error) {
+	var id int
+	err := inTx(db, func(tx *Tx) error {
+		var err error
+		id, err = addCompletion(tx, c)
+		if err != nil {
+			return err
+		}
+
+		query := `UPDATE scheduler SET date = ?, repeat = ?, done_count = done_count + 1
+			WHERE id = ? AND date = ? AND ` + activeTask
+		args := []interface{}{nextDate.Format("20060102"), repeat, c.TaskID, taskDate.Format("20060102")}
+		if nextDate.IsZero() {
+			query = `UPDATE scheduler SET archived_at = ? WHERE id = ? AND date = ? AND ` + activeTask
+			args = []interface{}{c.CompletedAt.UTC().Format(time.RFC3339), c.TaskID, taskDate.Format("20060102")}
+		}
+		result, err := tx.Exec(query, args...)
+		if err != nil {
+			return fmt.Errorf("ошибка при обновлении даты задачи: %w", err)
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+		}
+		if rowsAffected > 0 {
+			return nil
+		}
+
+		// Задача не изменилась: её нет среди активных или у неё уже другая дата
+		var count int
+		err = tx.QueryRow(`SELECT COUNT(*) FROM scheduler WHERE id = ? AND `+activeTask, c.TaskID).Scan(&count)
+		if err != nil {
+			return fmt.Errorf("ошибка при проверке задачи: %w", err)
+		}
+		if count == 0 {
+			return ErrTaskNotFound
+		}
+		return ErrTaskChanged
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
 }
-func DeleteTask(db *sql.DB, taskID int) error {
-	query := `DELETE FROM scheduler WHERE id = ?`
-	_, err := db.Exec(query, taskID)
-	return err
+
+// DeleteTask перемещает задачу в корзину
+func DeleteTask(db *DB, taskID int, now time.Time) error {
+	return removeTask(db, "deleted_at", taskID, now)
 }
@@ -0,0 +1,124 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+)
+
+// dialect — особенности SQL базы данных. Запросы пакета пишутся с параметрами ?
+// и в синтаксисе SQLite, а для PostgreSQL переводятся при выполнении.
+type dialect struct {
+	postgres bool
+}
+
+// rebind подставляет в запрос параметры PostgreSQL $1, $2, … вместо ?.
+// Знаки ? внутри строк и идентификаторов в кавычках не заменяются.
+func (d dialect) rebind(query string) string {
+	if !d.postgres || !strings.Contains(query, "?") {
+		return query
+	}
+	var b strings.Builder
+	n := 0
+	var quote rune
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			// Удвоенная кавычка внутри строки закрывает и сразу открывает её снова
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// schema переводит описание таблиц из синтаксиса SQLite в синтаксис базы
+func (d dialect) schema(query string) string {
+	if !d.postgres {
+		return query
+	}
+	return strings.ReplaceAll(query, "INTEGER PRIMARY KEY AUTOINCREMENT", "SERIAL PRIMARY KEY")
+}
+
+// likeOperator возвращает оператор поиска по подстроке без учёта регистра,
+// как LIKE в SQLite
+func (d dialect) likeOperator() string {
+	if d.postgres {
+		return "ILIKE"
+	}
+	return "LIKE"
+}
+
+// DB — подключение к базе SQLite или PostgreSQL. Exec, Query и QueryRow принимают
+// запросы с параметрами ? и переводят их в синтаксис своей базы, поэтому в одном
+// процессе можно работать с базами разных типов.
+type DB struct {
+	*sql.DB
+	dialect
+	// fullTextSearch показывает, что в базе настроен полнотекстовый индекс FTS5
+	fullTextSearch bool
+}
+
+// Postgres сообщает, что данные хранятся в PostgreSQL
+func (db *DB) Postgres() bool {
+	return db.postgres
+}
+
+// FullTextSearch сообщает, доступен ли полнотекстовый поиск; без него
+// поиск выполняется по подстроке в заголовке и комментарии
+func (db *DB) FullTextSearch() bool {
+	return db.fullTextSearch
+}
+
+func (db *DB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.rebind(query), args...)
+}
+
+func (db *DB) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return db.DB.Query(db.rebind(query), args...)
+}
+
+func (db *DB) QueryRow(query string, args ...interface{}) *sql.Row {
+	return db.DB.QueryRow(db.rebind(query), args...)
+}
+
+// Begin начинает транзакцию с тем же диалектом, что и у базы
+func (db *DB) Begin() (*Tx, error) {
+	tx, err := db.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.dialect}, nil
+}
+
+// execer — подключение или транзакция, в которых выполняются запросы
+type execer interface {
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+// Tx — транзакция, которая, как и DB, переводит запросы в синтаксис своей базы
+type Tx struct {
+	*sql.Tx
+	dialect
+}
+
+func (tx *Tx) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return tx.Tx.Exec(tx.rebind(query), args...)
+}
+
+func (tx *Tx) Query(query string, args ...interface{}) (*sql.Rows, error) {
+	return tx.Tx.Query(tx.rebind(query), args...)
+}
+
+func (tx *Tx) QueryRow(query string, args ...interface{}) *sql.Row {
+	return tx.Tx.QueryRow(tx.rebind(query), args...)
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// exceptionsSchema — таблица исключений для отдельных повторений задач
+const exceptionsSchema = `
+	CREATE TABLE IF NOT EXISTS exceptions (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		date TEXT NOT NULL,
+		skip INTEGER NOT NULL DEFAULT 0,
+		new_date TEXT NOT NULL DEFAULT '',
+		title TEXT NOT NULL DEFAULT '',
+		comment TEXT NOT NULL DEFAULT '',
+		UNIQUE (task_id, date)
+	);
+	CREATE INDEX IF NOT EXISTS idx_exceptions_task ON exceptions (task_id);
+`
+
+// ErrExceptionNotFound возвращается, если исключения нет в базе
+var ErrExceptionNotFound = errors.New("Исключение не найдено")
+
+// Exception — исключение для одного повторения задачи с датой Date:
+// отмена повторения (Skip) или перенос на NewDate с другими заголовком и комментарием
+type Exception struct {
+	ID      int
+	TaskID  int
+	Date    time.Time
+	Skip    bool
+	NewDate time.Time
+	Title   string
+	Comment string
+}
+
+// SaveException добавляет исключение или заменяет существующее для той же даты
+// повторения и возвращает его идентификатор. Ненулевая nextDate в той же транзакции
+// переносит задачу на эту дату с правилом repeat, не меняя счётчик выполнений.
+func SaveException(db *DB, e Exception, nextDate time.Time, repeat string) (int, error) {
+	query := `
+INSERT INTO exceptions (task_id, date, skip, new_date, title, comment)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (task_id, date) DO UPDATE
+SET skip = excluded.skip, new_date = excluded.new_date, title = excluded.title, comment = excluded.comment
+RETURNING id
+	`
+
+	// Отмена хранится числом: в PostgreSQL столбец skip целочисленный, как и в SQLite
+	skip := 0
+	if e.Skip {
+		skip = 1
+	}
+	var id int
+	err := inTx(db, func(tx *Tx) error {
+		err := tx.QueryRow(query, e.TaskID, e.Date.Format("20060102"), skip,
+			formatOptionalDate(e.NewDate), e.Title, e.Comment).Scan(&id)
+		if err != nil {
+			return fmt.Errorf("ошибка при сохранении исключения: %w", err)
+		}
+		if nextDate.IsZero() {
+			return nil
+		}
+		query := `UPDATE scheduler SET date = ?, repeat = ? WHERE id = ? AND ` + activeTask
+		if _, err := tx.Exec(query, nextDate.Format("20060102"), repeat, e.TaskID); err != nil {
+			return fmt.Errorf("ошибка при обновлении даты задачи: %w", err)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// GetExceptions возвращает исключения задачи, упорядоченные по дате повторения
+func GetExceptions(db *DB, taskID int) ([]Exception, error) {
+	query := `SELECT id, task_id, date, skip, new_date, title, comment FROM exceptions WHERE task_id = ? ORDER BY date ASC`
+	rows, err := db.Query(query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении исключений: %w", err)
+	}
+	defer rows.Close()
+
+	var exceptions []Exception
+	for rows.Next() {
+		var e Exception
+		var dateString, newDateString string
+		if err := rows.Scan(&e.ID, &e.TaskID, &dateString, &e.Skip, &newDateString, &e.Title, &e.Comment); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении исключения: %w", err)
+		}
+		e.Date, err = time.Parse("20060102", dateString)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при преобразовании даты")
+		}
+		if newDateString != "" {
+			e.NewDate, err = time.Parse("20060102", newDateString)
+			if err != nil {
+				return nil, fmt.Errorf("Ошибка при преобразовании даты")
+			}
+		}
+		exceptions = append(exceptions, e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении исключений: %w", err)
+	}
+	return exceptions, nil
+}
+
+// DeleteException удаляет исключение по идентификатору
+func DeleteException(db *DB, id int) error {
+	result, err := db.Exec(`DELETE FROM exceptions WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении исключения: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrExceptionNotFound
+	}
+	return nil
+}
+
+// ExceptionTaskID возвращает идентификатор задачи, к которой относится исключение
+func ExceptionTaskID(db *DB, id int) (int, error) {
+	var taskID int
+	err := db.QueryRow(`SELECT task_id FROM exceptions WHERE id = ?`, id).Scan(&taskID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrExceptionNotFound
+		}
+		return 0, fmt.Errorf("ошибка при получении исключения: %w", err)
+	}
+	return taskID, nil
+}
@@ -0,0 +1,85 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// historySchema — история выполнения задач. Заголовок и владелец задачи копируются
+// в запись, чтобы история одноразовой задачи оставалась доступной после её удаления.
+const historySchema = `
+	CREATE TABLE IF NOT EXISTS task_history (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		task_id INTEGER NOT NULL,
+		owner_id INTEGER NOT NULL DEFAULT 0,
+		title TEXT NOT NULL DEFAULT '',
+		date TEXT NOT NULL,
+		completed_at TEXT NOT NULL,
+		user_id INTEGER NOT NULL DEFAULT 0,
+		note TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_task_history_task ON task_history (task_id);
+`
+
+// Completion — отметка о выполнении задачи: дата Date, на которую задача была назначена,
+// время выполнения CompletedAt и пользователь UserID с необязательной заметкой
+type Completion struct {
+	ID          int
+	TaskID      int
+	OwnerID     int
+	Title       string
+	Date        time.Time
+	CompletedAt time.Time
+	UserID      int
+	Note        string
+}
+
+// addCompletion добавляет отметку о выполнении задачи и возвращает её идентификатор
+func addCompletion(db execer, c Completion) (int, error) {
+	query := `
+INSERT INTO task_history (task_id, owner_id, title, date, completed_at, user_id, note)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING id
+	`
+
+	var id int
+	err := db.QueryRow(query, c.TaskID, c.OwnerID, c.Title, c.Date.Format("20060102"),
+		c.CompletedAt.UTC().Format(time.RFC3339), c.UserID, c.Note).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при сохранении выполнения задачи: %w", err)
+	}
+	return id, nil
+}
+
+// GetHistory возвращает историю выполнения задачи, начиная с последнего выполнения
+func GetHistory(db *DB, taskID int) ([]Completion, error) {
+	query := `
+SELECT id, task_id, owner_id, title, date, completed_at, user_id, note
+FROM task_history WHERE task_id = ? ORDER BY completed_at DESC, id DESC
+	`
+	rows, err := db.Query(query, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении истории задачи: %w", err)
+	}
+	defer rows.Close()
+
+	var history []Completion
+	for rows.Next() {
+		var c Completion
+		var dateString, completedAt string
+		if err := rows.Scan(&c.ID, &c.TaskID, &c.OwnerID, &c.Title, &dateString, &completedAt,
+			&c.UserID, &c.Note); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении истории задачи: %w", err)
+		}
+		c.Date, err = time.Parse("20060102", dateString)
+		if err != nil {
+			return nil, fmt.Errorf("Ошибка при преобразовании даты")
+		}
+		c.CompletedAt, _ = time.Parse(time.RFC3339, completedAt)
+		history = append(history, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении истории задачи: %w", err)
+	}
+	return history, nil
+}
@@ -0,0 +1,262 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// listsSchema — общие списки задач и их участники
+const listsSchema = `
+	CREATE TABLE IF NOT EXISTS lists (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL,
+		owner_id INTEGER NOT NULL
+	);
+	CREATE TABLE IF NOT EXISTS list_members (
+		list_id INTEGER NOT NULL,
+		user_id INTEGER NOT NULL,
+		role TEXT NOT NULL DEFAULT 'viewer',
+		PRIMARY KEY (list_id, user_id)
+	);
+	CREATE INDEX IF NOT EXISTS idx_list_members_user ON list_members (user_id);
+	CREATE INDEX IF NOT EXISTS idx_list ON scheduler (list_id);
+`
+
+// Роли в списке: владелец управляет списком и участниками, редактор изменяет задачи,
+// наблюдатель только читает их
+const (
+	ListRoleOwner  = "owner"
+	ListRoleEditor = "editor"
+	ListRoleViewer = "viewer"
+)
+
+// ErrListNotFound возвращается, если списка нет в базе
+var ErrListNotFound = errors.New("Список не найден")
+
+// ErrListMemberNotFound возвращается, если пользователь не участвует в списке
+var ErrListMemberNotFound = errors.New("Участник не найден")
+
+// ErrListOwnerMember возвращается при попытке пригласить в список его владельца
+var ErrListOwnerMember = errors.New("Владелец уже имеет полный доступ к списку")
+
+type List struct {
+	ID      int
+	Name    string
+	OwnerID int
+	// Role — роль пользователя, для которого получен список
+	Role string
+}
+
+type ListMember struct {
+	UserID int
+	Login  string
+	Role   string
+}
+
+// CreateList создаёт список и возвращает его идентификатор
+func CreateList(db *DB, name string, ownerID int) (int, error) {
+	var id int
+	err := db.QueryRow(`INSERT INTO lists (name, owner_id) VALUES (?, ?) RETURNING id`, name, ownerID).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании списка: %w", err)
+	}
+	return id, nil
+}
+
+// GetList возвращает список по идентификатору
+func GetList(db *DB, id int) (*List, error) {
+	var list List
+	err := db.QueryRow(`SELECT id, name, owner_id FROM lists WHERE id = ?`, id).Scan(&list.ID, &list.Name, &list.OwnerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrListNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении списка: %w", err)
+	}
+	return &list, nil
+}
+
+// GetLists возвращает списки, которыми владеет пользователь или в которых он участвует,
+// с его ролью; при all — все списки
+func GetLists(db *DB, userID int, all bool) ([]List, error) {
+	query := `
+SELECT l.id, l.name, l.owner_id,
+	CASE WHEN l.owner_id = ? THEN 'owner' ELSE COALESCE(m.role, '') END
+FROM lists l
+LEFT JOIN list_members m ON m.list_id = l.id AND m.user_id = ?
+WHERE ? OR l.owner_id = ? OR m.user_id IS NOT NULL
+ORDER BY l.name, l.id
+	`
+	rows, err := db.Query(query, userID, userID, all, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении списков: %w", err)
+	}
+	defer rows.Close()
+
+	var lists []List
+	for rows.Next() {
+		var list List
+		if err := rows.Scan(&list.ID, &list.Name, &list.OwnerID, &list.Role); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении списков: %w", err)
+		}
+		lists = append(lists, list)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении списков: %w", err)
+	}
+	return lists, nil
+}
+
+// RenameList меняет название списка
+func RenameList(db *DB, id int, name string) error {
+	result, err := db.Exec(`UPDATE lists SET name = ? WHERE id = ?`, name, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении списка: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrListNotFound
+	}
+	return nil
+}
+
+// DeleteList удаляет список; его задачи остаются личными задачами их владельцев
+func DeleteList(db *DB, id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении списка: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM lists WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении списка: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrListNotFound
+	}
+	if _, err := tx.Exec(`DELETE FROM list_members WHERE list_id = ?`, id); err != nil {
+		return fmt.Errorf("ошибка при удалении участников списка: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE scheduler SET list_id = 0, assignee_id = 0 WHERE list_id = ?`, id); err != nil {
+		return fmt.Errorf("ошибка при обновлении задач списка: %w", err)
+	}
+	return tx.Commit()
+}
+
+// GetListMembers возвращает участников списка, упорядоченных по логину
+func GetListMembers(db *DB, listID int) ([]ListMember, error) {
+	query := `
+SELECT m.user_id, u.login, m.role
+FROM list_members m JOIN users u ON u.id = m.user_id
+WHERE m.list_id = ?
+ORDER BY u.login
+	`
+	rows, err := db.Query(query, listID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении участников списка: %w", err)
+	}
+	defer rows.Close()
+
+	var members []ListMember
+	for rows.Next() {
+		var member ListMember
+		if err := rows.Scan(&member.UserID, &member.Login, &member.Role); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении участников списка: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении участников списка: %w", err)
+	}
+	return members, nil
+}
+
+// SetListMember добавляет в список пользователя с логином login или меняет его роль
+// и возвращает идентификатор пользователя
+func SetListMember(db *DB, listID int, login, role string) (int, error) {
+	var userID int
+	err := inTx(db, func(tx *Tx) error {
+		var ownerID int
+		err := tx.QueryRow(`SELECT owner_id FROM lists WHERE id = ?`, listID).Scan(&ownerID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrListNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка при получении списка: %w", err)
+		}
+		err = tx.QueryRow(`SELECT id FROM users WHERE login = ?`, login).Scan(&userID)
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		if err != nil {
+			return fmt.Errorf("ошибка при получении пользователя: %w", err)
+		}
+		if userID == ownerID {
+			return ErrListOwnerMember
+		}
+
+		query := `
+INSERT INTO list_members (list_id, user_id, role) VALUES (?, ?, ?)
+ON CONFLICT (list_id, user_id) DO UPDATE SET role = excluded.role
+	`
+		if _, err := tx.Exec(query, listID, userID, role); err != nil {
+			return fmt.Errorf("ошибка при добавлении участника списка: %w", err)
+		}
+		return nil
+	})
+	return userID, err
+}
+
+// RemoveListMember исключает участника из списка и снимает с него задачи списка
+func RemoveListMember(db *DB, listID, userID int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении участника списка: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`DELETE FROM list_members WHERE list_id = ? AND user_id = ?`, listID, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении участника списка: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrListMemberNotFound
+	}
+	_, err = tx.Exec(`UPDATE scheduler SET assignee_id = 0 WHERE list_id = ? AND assignee_id = ?`, listID, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при обновлении задач списка: %w", err)
+	}
+	return tx.Commit()
+}
+
+// ListRole возвращает роль пользователя в списке или пустую строку, если он не участник
+func ListRole(db *DB, listID, userID int) (string, error) {
+	query := `
+SELECT CASE WHEN l.owner_id = ? THEN 'owner' ELSE COALESCE(m.role, '') END
+FROM lists l
+LEFT JOIN list_members m ON m.list_id = l.id AND m.user_id = ?
+WHERE l.id = ?
+	`
+	var role string
+	err := db.QueryRow(query, userID, userID, listID).Scan(&role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return "", ErrListNotFound
+		}
+		return "", fmt.Errorf("ошибка при получении роли в списке: %w", err)
+	}
+	return role, nil
+}
@@ -0,0 +1,382 @@
+package database
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MemoryStore — хранилище задач в памяти процесса для тестов и встраивания.
+// Общих списков в нём нет (ListStore не реализован), поиск выполняется по подстроке.
+type MemoryStore struct {
+	mu              sync.Mutex
+	nextID          int
+	tasks           map[int]Task
+	history         []Completion
+	nextExceptionID int
+	exceptions      map[int]Exception
+}
+
+// NewMemoryStore возвращает пустое хранилище задач в памяти
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{nextID: 1, tasks: make(map[int]Task), nextExceptionID: 1, exceptions: make(map[int]Exception)}
+}
+
+func (m *MemoryStore) CreateTask(task Task) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task.ID = m.nextID
+	task.DoneCount = 0
+	m.nextID++
+	m.tasks[task.ID] = task
+	return task.ID, nil
+}
+
+func (m *MemoryStore) GetTask(id int) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok || task.removed() {
+		return nil, ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+func (m *MemoryStore) UpdateTask(task Task) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.tasks[task.ID]
+	if !ok || current.removed() {
+		return ErrTaskNotFound
+	}
+	task.OwnerID = current.OwnerID
+	task.DoneCount = current.DoneCount
+	m.tasks[task.ID] = task
+	return nil
+}
+
+func (m *MemoryStore) DeleteTask(id int) error {
+	return m.remove(id, func(task *Task) { task.DeletedAt = time.Now() })
+}
+
+func (m *MemoryStore) remove(id int, mark func(task *Task)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok || task.removed() {
+		return ErrTaskNotFound
+	}
+	mark(&task)
+	m.tasks[id] = task
+	return nil
+}
+
+func (m *MemoryStore) GetRemovedTask(id int) (*Task, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok || !task.removed() {
+		return nil, ErrTaskNotFound
+	}
+	return &task, nil
+}
+
+func (m *MemoryStore) RestoreTask(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok || !task.removed() {
+		return ErrTaskNotFound
+	}
+	task.DeletedAt, task.ArchivedAt = time.Time{}, time.Time{}
+	m.tasks[id] = task
+	return nil
+}
+
+func (m *MemoryStore) PurgeTask(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[id]
+	if !ok || !task.removed() {
+		return ErrTaskNotFound
+	}
+	delete(m.tasks, id)
+	m.purgeExceptions(id)
+	return nil
+}
+
+func (m *MemoryStore) PurgeDeleted(before time.Time) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	purged := 0
+	for id, task := range m.tasks {
+		if !task.DeletedAt.IsZero() && task.DeletedAt.Before(before) {
+			delete(m.tasks, id)
+			m.purgeExceptions(id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (m *MemoryStore) CompleteTask(c Completion, taskDate, nextDate time.Time, repeat string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	task, ok := m.tasks[c.TaskID]
+	if !ok || task.removed() {
+		return 0, ErrTaskNotFound
+	}
+	if !task.Date.Equal(taskDate) {
+		return 0, ErrTaskChanged
+	}
+	if nextDate.IsZero() {
+		task.ArchivedAt = c.CompletedAt
+	} else {
+		task.Date = nextDate
+		task.Repeat = repeat
+		task.DoneCount++
+	}
+	m.tasks[c.TaskID] = task
+
+	c.ID = len(m.history) + 1
+	m.history = append(m.history, c)
+	return c.ID, nil
+}
+
+func (m *MemoryStore) History(taskID int) ([]Completion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var history []Completion
+	for i := len(m.history) - 1; i >= 0; i-- {
+		if m.history[i].TaskID == taskID {
+			history = append(history, m.history[i])
+		}
+	}
+	return history, nil
+}
+
+// purgeExceptions удаляет исключения окончательно удалённой задачи
+func (m *MemoryStore) purgeExceptions(taskID int) {
+	for id, e := range m.exceptions {
+		if e.TaskID == taskID {
+			delete(m.exceptions, id)
+		}
+	}
+}
+
+func (m *MemoryStore) Exceptions(taskID int) ([]Exception, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var exceptions []Exception
+	for _, e := range m.exceptions {
+		if e.TaskID == taskID {
+			exceptions = append(exceptions, e)
+		}
+	}
+	sort.Slice(exceptions, func(i, j int) bool {
+		return exceptions[i].Date.Before(exceptions[j].Date)
+	})
+	return exceptions, nil
+}
+
+func (m *MemoryStore) SaveException(e Exception, nextDate time.Time, repeat string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e.ID = 0
+	for id, current := range m.exceptions {
+		if current.TaskID == e.TaskID && current.Date.Equal(e.Date) {
+			e.ID = id
+			break
+		}
+	}
+	if e.ID == 0 {
+		e.ID = m.nextExceptionID
+		m.nextExceptionID++
+	}
+	m.exceptions[e.ID] = e
+
+	if task, ok := m.tasks[e.TaskID]; ok && !task.removed() && !nextDate.IsZero() {
+		task.Date = nextDate
+		task.Repeat = repeat
+		m.tasks[e.TaskID] = task
+	}
+	return e.ID, nil
+}
+
+func (m *MemoryStore) ExceptionTaskID(id int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	e, ok := m.exceptions[id]
+	if !ok {
+		return 0, ErrExceptionNotFound
+	}
+	return e.TaskID, nil
+}
+
+func (m *MemoryStore) DeleteException(id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.exceptions[id]; !ok {
+		return ErrExceptionNotFound
+	}
+	delete(m.exceptions, id)
+	return nil
+}
+
+// withException подставляет в задачу перенесённую дату, заголовок и комментарий
+// текущего повторения из исключения
+func (m *MemoryStore) withException(task Task) Task {
+	for _, e := range m.exceptions {
+		if e.TaskID != task.ID || !e.Date.Equal(task.Date) || e.Skip {
+			continue
+		}
+		if !e.NewDate.IsZero() {
+			task.Date = e.NewDate
+		}
+		if e.Title != "" {
+			task.Title = e.Title
+		}
+		if e.Comment != "" {
+			task.Comment = e.Comment
+		}
+		break
+	}
+	return task
+}
+
+func (m *MemoryStore) ListRole(listID, userID int) (string, error) {
+	return "", ErrListNotFound
+}
+
+func (m *MemoryStore) FullTextSearch() bool {
+	return false
+}
+
+func (m *MemoryStore) ListTasks(filter TaskFilter) (TaskPage, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	search := strings.ToLower(filter.Search)
+	var matched []ListedTask
+	for _, stored := range m.tasks {
+		// Поиск идёт по сохранённым заголовку и комментарию, остальные условия — по текущему повторению
+		task := m.withException(stored)
+		switch {
+		case filter.State == TasksActive && task.removed(),
+			filter.State == TasksDeleted && task.DeletedAt.IsZero(),
+			filter.State == TasksArchived && (task.ArchivedAt.IsZero() || !task.DeletedAt.IsZero()):
+			continue
+		case !filter.All && task.OwnerID != filter.UserID:
+			continue
+		case !filter.Date.IsZero() && !task.Date.Equal(filter.Date):
+			continue
+		case search != "" && !strings.Contains(strings.ToLower(stored.Title), search) &&
+			!strings.Contains(strings.ToLower(stored.Comment), search):
+			continue
+		case filter.ListID != nil && task.ListID != *filter.ListID:
+			continue
+		case filter.AssigneeID != nil && task.AssigneeID != *filter.AssigneeID:
+			continue
+		case !filter.From.IsZero() && task.Date.Before(filter.From):
+			continue
+		case !filter.To.IsZero() && task.Date.After(filter.To):
+			continue
+		case filter.Repeating != nil && (task.Repeat != "") != *filter.Repeating:
+			continue
+		}
+
+		item := ListedTask{Task: task}
+		switch filter.Sort {
+		case "date":
+			item.SortKey = task.Date.Format("20060102") + " " + task.Time
+		case "title":
+			item.SortKey = task.Title
+		case "id":
+			item.SortKey = int64(task.ID)
+		case "deleted":
+			item.SortKey = task.DeletedAt.Unix()
+		case "archived":
+			item.SortKey = task.ArchivedAt.Unix()
+		default:
+			return TaskPage{}, fmt.Errorf("сортировка %q не поддерживается", filter.Sort)
+		}
+		matched = append(matched, item)
+	}
+
+	// Задачи упорядочиваются по полю сортировки, а при равных значениях — по идентификатору
+	less := func(key interface{}, id int, other ListedTask) bool {
+		c := compareSortKeys(key, other.SortKey)
+		if filter.Desc {
+			c = -c
+		}
+		if c == 0 {
+			if filter.Desc {
+				return id > other.ID
+			}
+			return id < other.ID
+		}
+		return c < 0
+	}
+	sort.Slice(matched, func(i, j int) bool {
+		return less(matched[i].SortKey, matched[i].ID, matched[j])
+	})
+
+	page := TaskPage{Total: len(matched)}
+	for _, task := range matched {
+		if filter.After != nil && !less(filter.After.Key, filter.After.ID, task) {
+			continue
+		}
+		if len(page.Tasks) == filter.Limit {
+			page.More = true
+			break
+		}
+		page.Tasks = append(page.Tasks, task)
+	}
+	if !filter.CountTotal {
+		page.Total = 0
+	}
+	return page, nil
+}
+
+// compareSortKeys сравнивает значения поля сортировки: строки или числа,
+// в том числе числа из курсора, прочитанного из JSON
+func compareSortKeys(a, b interface{}) int {
+	if as, ok := a.(string); ok {
+		bs, _ := b.(string)
+		return strings.Compare(as, bs)
+	}
+	af, bf := sortKeyNumber(a), sortKeyNumber(b)
+	switch {
+	case af < bf:
+		return -1
+	case af > bf:
+		return 1
+	}
+	return 0
+}
+
+func sortKeyNumber(v interface{}) float64 {
+	switch n := v.(type) {
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	}
+	return 0
+}
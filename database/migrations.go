@@ -0,0 +1,341 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"time"
+)
+
+// schemaVersionSchema — применённые миграции схемы базы данных
+const schemaVersionSchema = `
+	CREATE TABLE IF NOT EXISTS schema_version (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TEXT NOT NULL
+	);
+`
+
+// schedulerSchema — таблица задач в исходном виде, остальные столбцы добавляются миграциями
+const schedulerSchema = `
+	CREATE TABLE IF NOT EXISTS scheduler (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		date TEXT NOT NULL DEFAULT '',
+		title TEXT NOT NULL DEFAULT '',
+		comment TEXT NOT NULL DEFAULT '',
+		repeat VARCHAR(128) NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_date ON scheduler (date);
+`
+
+// migration — шаг изменения схемы с откатом. Шаги up проверяют наличие таблиц и столбцов,
+// поэтому применимы и к базам, обновлённым прежними версиями приложения без учёта версий схемы.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *Tx) error
+	down    func(tx *Tx) error
+}
+
+// column — столбец, добавляемый миграцией
+type column struct {
+	name       string
+	definition string
+}
+
+// migrations — миграции схемы по возрастанию версии, общие для SQLite и PostgreSQL.
+// Таблицы описываются в синтаксисе SQLite и переводятся для PostgreSQL в execSQL.
+// Новые миграции добавляются только в конец.
+// Полнотекстовый индекс зависит от сборки SQLite и настраивается при каждом запуске в setupSearch.
+var migrations = []migration{
+	{
+		version: 1,
+		name:    "Таблица задач",
+		up:      execSQL(schedulerSchema),
+		down:    execSQL(`DROP INDEX IF EXISTS idx_date; DROP TABLE IF EXISTS scheduler;`),
+	},
+	{
+		version: 2,
+		name:    "Ограничение количества повторений",
+		up: addColumns("scheduler",
+			column{"repeat_until", `TEXT NOT NULL DEFAULT ''`},
+			column{"repeat_count", "INTEGER NOT NULL DEFAULT 0"},
+			column{"done_count", "INTEGER NOT NULL DEFAULT 0"},
+		),
+		down: dropColumns("scheduler", "repeat_until", "repeat_count", "done_count"),
+	},
+	{
+		version: 3,
+		name:    "Исключения для повторений",
+		up:      execSQL(exceptionsSchema),
+		down:    execSQL(`DROP INDEX IF EXISTS idx_exceptions_task; DROP TABLE IF EXISTS exceptions;`),
+	},
+	{
+		version: 4,
+		name:    "Время и часовой пояс задачи",
+		up: addColumns("scheduler",
+			column{"due_time", `TEXT NOT NULL DEFAULT ''`},
+			column{"timezone", `TEXT NOT NULL DEFAULT ''`},
+		),
+		down: dropColumns("scheduler", "due_time", "timezone"),
+	},
+	{
+		version: 5,
+		name:    "Режим повторения",
+		up:      addColumns("scheduler", column{"repeat_mode", `TEXT NOT NULL DEFAULT 'due'`}),
+		down:    dropColumns("scheduler", "repeat_mode"),
+	},
+	{
+		version: 6,
+		name:    "Пользователи и владельцы задач",
+		up: steps(
+			addColumns("scheduler", column{"owner_id", "INTEGER NOT NULL DEFAULT 0"}),
+			execSQL(usersSchema),
+		),
+		down: steps(
+			execSQL(`DROP INDEX IF EXISTS idx_owner; DROP TABLE IF EXISTS users;`),
+			dropColumns("scheduler", "owner_id"),
+		),
+	},
+	{
+		version: 7,
+		name:    "Общие списки и исполнители задач",
+		up: steps(
+			addColumns("scheduler",
+				column{"list_id", "INTEGER NOT NULL DEFAULT 0"},
+				column{"assignee_id", "INTEGER NOT NULL DEFAULT 0"},
+			),
+			execSQL(listsSchema),
+		),
+		down: steps(
+			execSQL(`DROP INDEX IF EXISTS idx_list; DROP INDEX IF EXISTS idx_list_members_user;
+				DROP TABLE IF EXISTS list_members; DROP TABLE IF EXISTS lists;`),
+			dropColumns("scheduler", "list_id", "assignee_id"),
+		),
+	},
+	{
+		version: 8,
+		name:    "Персональные токены API",
+		up:      execSQL(tokensSchema),
+		down:    execSQL(`DROP INDEX IF EXISTS idx_api_tokens_user; DROP TABLE IF EXISTS api_tokens;`),
+	},
+	{
+		version: 9,
+		name:    "История выполнения задач",
+		up:      execSQL(historySchema),
+		down:    execSQL(`DROP INDEX IF EXISTS idx_task_history_task; DROP TABLE IF EXISTS task_history;`),
+	},
+	{
+		version: 10,
+		name:    "Корзина и архив задач",
+		up: addColumns("scheduler",
+			column{"deleted_at", `TEXT NOT NULL DEFAULT ''`},
+			column{"archived_at", `TEXT NOT NULL DEFAULT ''`},
+		),
+		down: dropColumns("scheduler", "deleted_at", "archived_at"),
+	},
+}
+
+// MigrationStatus — миграция и время её применения; пустое время — миграция не применена
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt string
+}
+
+// LatestVersion возвращает версию схемы, которую использует приложение
+func LatestVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+// SchemaVersion возвращает версию схемы базы данных, 0 — миграции не применялись
+func SchemaVersion(db *DB) (int, error) {
+	if _, err := db.Exec(schemaVersionSchema); err != nil {
+		return 0, fmt.Errorf("ошибка при создании таблицы версий схемы: %w", err)
+	}
+	var version int
+	if err := db.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("ошибка при получении версии схемы: %w", err)
+	}
+	return version, nil
+}
+
+// Migrations возвращает все миграции с отметкой о применении
+func Migrations(db *DB) ([]MigrationStatus, error) {
+	if _, err := SchemaVersion(db); err != nil {
+		return nil, err
+	}
+	applied := make(map[int]string)
+	rows, err := db.Query(`SELECT version, applied_at FROM schema_version`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении версий схемы: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении версий схемы: %w", err)
+		}
+		applied[version] = appliedAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении версий схемы: %w", err)
+	}
+
+	var result []MigrationStatus
+	for _, m := range migrations {
+		result = append(result, MigrationStatus{Version: m.version, Name: m.name, AppliedAt: applied[m.version]})
+	}
+	return result, nil
+}
+
+// MigrateTo применяет или откатывает миграции, пока версия схемы не станет равной target.
+// Каждая миграция выполняется в отдельной транзакции.
+func MigrateTo(db *DB, target int) error {
+	if target < 0 || target > LatestVersion() {
+		return fmt.Errorf("версия схемы должна быть от 0 до %d", LatestVersion())
+	}
+	unlock, err := lockMigrations(db)
+	if err != nil {
+		return fmt.Errorf("ошибка при блокировке миграций: %w", err)
+	}
+	defer unlock()
+
+	current, err := SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if current > LatestVersion() {
+		return fmt.Errorf("версия схемы базы данных %d новее поддерживаемой %d", current, LatestVersion())
+	}
+
+	for _, m := range migrations {
+		if m.version <= current || m.version > target {
+			continue
+		}
+		log.Printf("Применение миграции %d: %s", m.version, m.name)
+		err := inTx(db, func(tx *Tx) error {
+			if err := m.up(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+				m.version, m.name, time.Now().UTC().Format(time.RFC3339))
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("ошибка при применении миграции %d: %w", m.version, err)
+		}
+	}
+
+	for i := len(migrations) - 1; i >= 0; i-- {
+		m := migrations[i]
+		if m.version > current || m.version <= target {
+			continue
+		}
+		log.Printf("Откат миграции %d: %s", m.version, m.name)
+		err := inTx(db, func(tx *Tx) error {
+			if err := m.down(tx); err != nil {
+				return err
+			}
+			_, err := tx.Exec(`DELETE FROM schema_version WHERE version = ?`, m.version)
+			return err
+		})
+		if err != nil {
+			return fmt.Errorf("ошибка при откате миграции %d: %w", m.version, err)
+		}
+	}
+	return nil
+}
+
+func inTx(db *DB, f func(tx *Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	if err := f(tx); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func steps(fs ...func(tx *Tx) error) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		for _, f := range fs {
+			if err := f(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+func execSQL(query string) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		_, err := tx.Exec(tx.schema(query))
+		return err
+	}
+}
+
+// addColumns добавляет в таблицу столбцы, которых в ней ещё нет
+func addColumns(table string, columns ...column) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		existing, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, c := range columns {
+			if existing[c.name] {
+				continue
+			}
+			if _, err := tx.Exec("ALTER TABLE " + table + " ADD COLUMN " + c.name + " " + c.definition); err != nil {
+				return fmt.Errorf("ошибка при добавлении столбца %s: %w", c.name, err)
+			}
+		}
+		return nil
+	}
+}
+
+// dropColumns удаляет столбцы из таблицы, если они в ней есть
+func dropColumns(table string, names ...string) func(tx *Tx) error {
+	return func(tx *Tx) error {
+		existing, err := tableColumns(tx, table)
+		if err != nil {
+			return err
+		}
+		for _, name := range names {
+			if !existing[name] {
+				continue
+			}
+			if _, err := tx.Exec("ALTER TABLE " + table + " DROP COLUMN " + name); err != nil {
+				return fmt.Errorf("ошибка при удалении столбца %s: %w", name, err)
+			}
+		}
+		return nil
+	}
+}
+
+func tableColumns(tx *Tx, table string) (map[string]bool, error) {
+	query := `SELECT name FROM pragma_table_info(?)`
+	if tx.postgres {
+		query = `SELECT column_name FROM information_schema.columns WHERE table_schema = current_schema() AND table_name = ?`
+	}
+	rows, err := tx.Query(query, table)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении структуры таблицы: %w", err)
+	}
+	defer rows.Close()
+
+	existing := make(map[string]bool)
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении структуры таблицы: %w", err)
+		}
+		existing[name] = true
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при чтении структуры таблицы: %w", err)
+	}
+	return existing, nil
+}
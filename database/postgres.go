@@ -0,0 +1,59 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lib/pq"
+)
+
+// uniqueViolation сообщает, что запись нарушила ограничение уникальности
+func uniqueViolation(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "23505"
+}
+
+// migrationsLock — ключ блокировки, под которой экземпляры приложения по очереди
+// применяют миграции к общей базе PostgreSQL
+const migrationsLock = 7540
+
+// lockMigrations ждёт, пока другие экземпляры закончат миграции, и возвращает функцию
+// снятия блокировки. В SQLite база принадлежит одному процессу, блокировка не нужна.
+func lockMigrations(db *DB) (func(), error) {
+	if !db.postgres {
+		return func() {}, nil
+	}
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, migrationsLock); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return func() {
+		conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, migrationsLock)
+		conn.Close()
+	}, nil
+}
+
+// PostgresStore — хранилище задач и списков в базе PostgreSQL. Запросы общие с SQLiteStore
+// и переводятся в синтаксис PostgreSQL подключением db, а поиск выполняется по подстроке
+// без учёта регистра: полнотекстового индекса нет.
+type PostgresStore struct {
+	sqlStore
+}
+
+// NewPostgresStore возвращает хранилище задач в открытой базе PostgreSQL
+func NewPostgresStore(db *DB) *PostgresStore {
+	return &PostgresStore{sqlStore{db: db}}
+}
+
+// NewStore возвращает хранилище задач и списков для базы db
+func NewStore(db *DB) Store {
+	if db.Postgres() {
+		return NewPostgresStore(db)
+	}
+	return NewSQLiteStore(db)
+}
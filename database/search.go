@@ -0,0 +1,93 @@
+package database
+
+import (
+	"fmt"
+	"log"
+	"strings"
+)
+
+// searchSchema — полнотекстовый индекс по заголовкам и комментариям задач.
+// Индекс хранит только токены, содержимое берётся из таблицы scheduler,
+// а триггеры поддерживают индекс в актуальном состоянии. В индекс попадают
+// только задачи вне корзины и архива, чтобы удалённые задачи не влияли
+// на релевантность.
+const searchSchema = `
+	CREATE VIRTUAL TABLE IF NOT EXISTS scheduler_fts USING fts5(
+		title, comment,
+		content = 'scheduler', content_rowid = 'id',
+		tokenize = 'unicode61 remove_diacritics 2'
+	);
+	CREATE TRIGGER scheduler_fts_insert AFTER INSERT ON scheduler
+	WHEN new.deleted_at = '' AND new.archived_at = '' BEGIN
+		INSERT INTO scheduler_fts (rowid, title, comment) VALUES (new.id, new.title, new.comment);
+	END;
+	CREATE TRIGGER scheduler_fts_delete AFTER DELETE ON scheduler
+	WHEN old.deleted_at = '' AND old.archived_at = '' BEGIN
+		INSERT INTO scheduler_fts (scheduler_fts, rowid, title, comment) VALUES ('delete', old.id, old.title, old.comment);
+	END;
+	CREATE TRIGGER scheduler_fts_update AFTER UPDATE OF title, comment, deleted_at, archived_at ON scheduler BEGIN
+		INSERT INTO scheduler_fts (scheduler_fts, rowid, title, comment)
+			SELECT 'delete', old.id, old.title, old.comment WHERE old.deleted_at = '' AND old.archived_at = '';
+		INSERT INTO scheduler_fts (rowid, title, comment)
+			SELECT new.id, new.title, new.comment WHERE new.deleted_at = '' AND new.archived_at = '';
+	END;
+	INSERT INTO scheduler_fts (scheduler_fts) VALUES ('delete-all');
+	INSERT INTO scheduler_fts (rowid, title, comment)
+		SELECT id, title, comment FROM scheduler WHERE deleted_at = '' AND archived_at = '';
+`
+
+// Без FTS5 триггеры ссылались бы на недоступную таблицу и ломали запись задач.
+// С FTS5 триггеры пересоздаются, чтобы обновить их в базах прежних версий.
+const dropSearchTriggers = `
+	DROP TRIGGER IF EXISTS scheduler_fts_insert;
+	DROP TRIGGER IF EXISTS scheduler_fts_update;
+	DROP TRIGGER IF EXISTS scheduler_fts_delete;
+`
+
+// setupSearch создаёт полнотекстовый индекс, если SQLite собран с FTS5
+// (тег сборки sqlite_fts5), и заполняет его заново на случай, если база
+// изменялась сборкой без FTS5
+func setupSearch(db *DB) error {
+	var enabled bool
+	if err := db.QueryRow("SELECT sqlite_compileoption_used('ENABLE_FTS5')").Scan(&enabled); err != nil {
+		return fmt.Errorf("ошибка при проверке поддержки FTS5: %w", err)
+	}
+
+	if !enabled {
+		log.Println("SQLite собран без FTS5, поиск задач выполняется по подстроке")
+		if _, err := db.Exec(dropSearchTriggers); err != nil {
+			return fmt.Errorf("ошибка при удалении триггеров поиска: %w", err)
+		}
+		return nil
+	}
+	if err := inTx(db, func(tx *Tx) error {
+		if _, err := tx.Exec(dropSearchTriggers); err != nil {
+			return err
+		}
+		_, err := tx.Exec(searchSchema)
+		return err
+	}); err != nil {
+		return fmt.Errorf("ошибка при создании полнотекстового индекса: %w", err)
+	}
+	db.fullTextSearch = true
+	return nil
+}
+
+// MatchQuery превращает строку поиска в запрос FTS5: каждое слово ищется
+// как префикс, все слова должны встретиться в задаче
+func MatchQuery(search string) string {
+	var terms []string
+	for _, word := range strings.Fields(search) {
+		terms = append(terms, `"`+strings.ReplaceAll(word, `"`, `""`)+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// likeEscaper экранирует символы шаблона LIKE обратной косой чертой
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// likePattern превращает строку поиска в шаблон LIKE для поиска подстроки с ESCAPE '\':
+// символы %, _ и \ в строке ищутся как обычные символы
+func likePattern(search string) string {
+	return "%" + likeEscaper.Replace(search) + "%"
+}
@@ -0,0 +1,274 @@
+package database
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// sqlStore — хранилище задач и списков в базе данных. Запросы написаны в синтаксисе SQLite
+// и переводятся в синтаксис своей базы подключением db.
+type sqlStore struct {
+	db *DB
+}
+
+// SQLiteStore — хранилище задач и списков в базе SQLite
+type SQLiteStore struct {
+	sqlStore
+}
+
+// NewSQLiteStore возвращает хранилище задач в открытой базе SQLite
+func NewSQLiteStore(db *DB) *SQLiteStore {
+	return &SQLiteStore{sqlStore{db: db}}
+}
+
+func (s *sqlStore) CreateTask(task Task) (int, error) {
+	return InsertTask(s.db, task)
+}
+
+func (s *sqlStore) GetTask(id int) (*Task, error) {
+	return GetTaskByID(s.db, strconv.Itoa(id))
+}
+
+func (s *sqlStore) UpdateTask(task Task) error {
+	return UpdateTask(s.db, task)
+}
+
+func (s *sqlStore) DeleteTask(id int) error {
+	return DeleteTask(s.db, id, time.Now())
+}
+
+func (s *sqlStore) GetRemovedTask(id int) (*Task, error) {
+	return GetRemovedTask(s.db, id)
+}
+
+func (s *sqlStore) RestoreTask(id int) error {
+	return RestoreTask(s.db, id)
+}
+
+func (s *sqlStore) PurgeTask(id int) error {
+	return PurgeTask(s.db, id)
+}
+
+func (s *sqlStore) PurgeDeleted(before time.Time) (int, error) {
+	return PurgeDeletedTasks(s.db, before)
+}
+
+func (s *sqlStore) CompleteTask(c Completion, taskDate, nextDate time.Time, repeat string) (int, error) {
+	return CompleteTask(s.db, c, taskDate, nextDate, repeat)
+}
+
+func (s *sqlStore) History(taskID int) ([]Completion, error) {
+	return GetHistory(s.db, taskID)
+}
+
+func (s *sqlStore) Exceptions(taskID int) ([]Exception, error) {
+	return GetExceptions(s.db, taskID)
+}
+
+func (s *sqlStore) SaveException(e Exception, nextDate time.Time, repeat string) (int, error) {
+	return SaveException(s.db, e, nextDate, repeat)
+}
+
+func (s *sqlStore) ExceptionTaskID(id int) (int, error) {
+	return ExceptionTaskID(s.db, id)
+}
+
+func (s *sqlStore) DeleteException(id int) error {
+	return DeleteException(s.db, id)
+}
+
+func (s *sqlStore) ListRole(listID, userID int) (string, error) {
+	return ListRole(s.db, listID, userID)
+}
+
+func (s *sqlStore) CreateList(name string, ownerID int) (int, error) {
+	return CreateList(s.db, name, ownerID)
+}
+
+func (s *sqlStore) GetList(id int) (*List, error) {
+	return GetList(s.db, id)
+}
+
+func (s *sqlStore) Lists(userID int, all bool) ([]List, error) {
+	return GetLists(s.db, userID, all)
+}
+
+func (s *sqlStore) RenameList(id int, name string) error {
+	return RenameList(s.db, id, name)
+}
+
+func (s *sqlStore) DeleteList(id int) error {
+	return DeleteList(s.db, id)
+}
+
+func (s *sqlStore) ListMembers(listID int) ([]ListMember, error) {
+	return GetListMembers(s.db, listID)
+}
+
+func (s *sqlStore) SetListMember(listID int, login, role string) (int, error) {
+	return SetListMember(s.db, listID, login, role)
+}
+
+func (s *sqlStore) RemoveListMember(listID, userID int) error {
+	return RemoveListMember(s.db, listID, userID)
+}
+
+func (s *sqlStore) FullTextSearch() bool {
+	return s.db.FullTextSearch()
+}
+
+// sqlSorts — выражения SQL для полей сортировки списка задач. Задачи на одну дату
+// упорядочиваются по времени, задачи без времени идут первыми. При расчёте
+// релевантности совпадение в заголовке весит больше, чем в комментарии.
+var sqlSorts = map[string]string{
+	"date":      "COALESCE(NULLIF(e.new_date, ''), s.date) || ' ' || s.due_time",
+	"title":     "COALESCE(NULLIF(e.title, ''), s.title)",
+	"id":        "s.id",
+	"deleted":   "s.deleted_at",
+	"archived":  "s.archived_at",
+	"relevance": "bm25(scheduler_fts, 5.0, 1.0)",
+}
+
+func (s *sqlStore) ListTasks(filter TaskFilter) (TaskPage, error) {
+	// Поиск по дате или по словам заголовка и комментария. Полнотекстовый поиск
+	// выделяет найденные слова, а без FTS5 задачи ищутся по подстроке.
+	from := "scheduler s"
+	snippet := "''"
+	state, ok := taskStates[filter.State]
+	if !ok {
+		return TaskPage{}, fmt.Errorf("состояние задач %q не поддерживается", filter.State)
+	}
+	conditions := []string{state}
+	var args []interface{}
+	if !filter.Date.IsZero() {
+		conditions = append(conditions, "COALESCE(NULLIF(e.new_date, ''), s.date) = ?")
+		args = append(args, filter.Date.Format("20060102"))
+	}
+	// В полнотекстовом индексе нет задач из корзины и архива, среди них ищется подстрока
+	fullText := filter.Search != "" && s.db.FullTextSearch() && filter.State == TasksActive
+	if filter.Search != "" {
+		if fullText {
+			from = "scheduler_fts JOIN scheduler s ON s.id = scheduler_fts.rowid"
+			snippet = "snippet(scheduler_fts, -1, '<mark>', '</mark>', '…', 12)"
+			conditions = append(conditions, "scheduler_fts MATCH ?")
+			args = append(args, MatchQuery(filter.Search))
+		} else {
+			conditions = append(conditions, "(s.title "+s.db.likeOperator()+` ? ESCAPE '\' OR s.comment `+s.db.likeOperator()+` ? ESCAPE '\')`)
+			pattern := likePattern(filter.Search)
+			args = append(args, pattern, pattern)
+		}
+	}
+	sortExpr := sqlSorts[filter.Sort]
+	if sortExpr == "" || (filter.Sort == "relevance" && !fullText) {
+		return TaskPage{}, fmt.Errorf("сортировка %q не поддерживается", filter.Sort)
+	}
+
+	if !filter.All {
+		conditions = append(conditions, `(s.owner_id = ? OR s.list_id IN (
+			SELECT id FROM lists WHERE owner_id = ? UNION SELECT list_id FROM list_members WHERE user_id = ?))`)
+		args = append(args, filter.UserID, filter.UserID, filter.UserID)
+	}
+	if filter.ListID != nil {
+		conditions = append(conditions, "s.list_id = ?")
+		args = append(args, *filter.ListID)
+	}
+	if filter.AssigneeID != nil {
+		conditions = append(conditions, "s.assignee_id = ?")
+		args = append(args, *filter.AssigneeID)
+	}
+	if !filter.From.IsZero() {
+		conditions = append(conditions, "COALESCE(NULLIF(e.new_date, ''), s.date) >= ?")
+		args = append(args, filter.From.Format("20060102"))
+	}
+	if !filter.To.IsZero() {
+		conditions = append(conditions, "COALESCE(NULLIF(e.new_date, ''), s.date) <= ?")
+		args = append(args, filter.To.Format("20060102"))
+	}
+	if filter.Repeating != nil {
+		if *filter.Repeating {
+			conditions = append(conditions, "s.repeat != ''")
+		} else {
+			conditions = append(conditions, "s.repeat = ''")
+		}
+	}
+	where := "WHERE " + strings.Join(conditions, " AND ")
+
+	var page TaskPage
+	if filter.CountTotal {
+		err := s.db.QueryRow(`
+			SELECT COUNT(*) FROM `+from+`
+			LEFT JOIN exceptions e ON e.task_id = s.id AND e.date = s.date AND e.skip = 0
+			`+where, args...).Scan(&page.Total)
+		if err != nil {
+			return TaskPage{}, fmt.Errorf("ошибка при подсчёте задач: %w", err)
+		}
+	}
+
+	// Страница начинается после задачи из курсора: по значению поля сортировки,
+	// а при равных значениях — по идентификатору
+	order, op := "ASC", ">"
+	if filter.Desc {
+		order, op = "DESC", "<"
+	}
+	pageCondition := ""
+	pageArgs := append([]interface{}{}, args...)
+	if filter.After != nil {
+		pageCondition = "WHERE sort_key " + op + " ? OR (sort_key = ? AND id " + op + " ?)"
+		pageArgs = append(pageArgs, filter.After.Key, filter.After.Key, filter.After.ID)
+	}
+
+	// Для текущего повторения подставляются перенесённая дата, заголовок и комментарий из исключений.
+	// Лишняя запись показывает, что за страницей есть ещё задачи.
+	rows, err := s.db.Query(`
+		SELECT id, effective_date, title, comment, repeat, repeat_until, repeat_count, done_count,
+			due_time, timezone, repeat_mode, owner_id, list_id, assignee_id, deleted_at, archived_at, snippet, sort_key
+		FROM (
+			SELECT s.id, COALESCE(NULLIF(e.new_date, ''), s.date) AS effective_date,
+				COALESCE(NULLIF(e.title, ''), s.title) AS title, COALESCE(NULLIF(e.comment, ''), s.comment) AS comment,
+				s.repeat, s.repeat_until, s.repeat_count, s.done_count, s.due_time, s.timezone, s.repeat_mode,
+				s.owner_id, s.list_id, s.assignee_id, s.deleted_at, s.archived_at, `+snippet+` AS snippet, `+sortExpr+` AS sort_key
+			FROM `+from+`
+			LEFT JOIN exceptions e ON e.task_id = s.id AND e.date = s.date AND e.skip = 0
+			`+where+`
+		) AS tasks
+		`+pageCondition+`
+		ORDER BY sort_key `+order+`, id `+order+` LIMIT ?`, append(pageArgs, filter.Limit+1)...)
+	if err != nil {
+		return TaskPage{}, fmt.Errorf("ошибка при получении задач: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		if len(page.Tasks) == filter.Limit {
+			page.More = true
+			break
+		}
+
+		var task ListedTask
+		var dateString, untilString, deletedAt, archivedAt string
+		if err := rows.Scan(&task.ID, &dateString, &task.Title, &task.Comment, &task.Repeat,
+			&untilString, &task.RepeatCount, &task.DoneCount, &task.Time, &task.Timezone, &task.RepeatMode,
+			&task.OwnerID, &task.ListID, &task.AssigneeID, &deletedAt, &archivedAt, &task.Snippet, &task.SortKey); err != nil {
+			return TaskPage{}, fmt.Errorf("ошибка при чтении задач: %w", err)
+		}
+		task.Date, err = time.Parse("20060102", dateString)
+		if err != nil {
+			return TaskPage{}, fmt.Errorf("ошибка при преобразовании даты задачи %d: %w", task.ID, err)
+		}
+		if untilString != "" {
+			task.RepeatUntil, err = time.Parse("20060102", untilString)
+			if err != nil {
+				return TaskPage{}, fmt.Errorf("ошибка при преобразовании даты задачи %d: %w", task.ID, err)
+			}
+		}
+		task.DeletedAt = parseOptionalTime(deletedAt)
+		task.ArchivedAt = parseOptionalTime(archivedAt)
+		page.Tasks = append(page.Tasks, task)
+	}
+	if err := rows.Err(); err != nil {
+		return TaskPage{}, fmt.Errorf("ошибка при переборе задач: %w", err)
+	}
+	return page, nil
+}
@@ -0,0 +1,139 @@
+package database
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrTaskNotFound возвращается, если задачи нет в хранилище
+var ErrTaskNotFound = errors.New("Задача не найдена")
+
+// ErrTaskChanged возвращается, если задачу успел перенести другой запрос,
+// например, повторная отметка о выполнении
+var ErrTaskChanged = errors.New("Задача уже перенесена на другую дату")
+
+// TaskStore — хранилище задач, с которым работают обработчики задач.
+// SQLiteStore хранит задачи в базе SQLite, PostgresStore — в PostgreSQL, MemoryStore — в памяти процесса.
+type TaskStore interface {
+	ListRoles
+	CreateTask(task Task) (int, error)
+	// GetTask возвращает задачу, если она не перемещена в корзину или архив
+	GetTask(id int) (*Task, error)
+	// UpdateTask изменяет задачу, не меняя её владельца и счётчик выполнений
+	UpdateTask(task Task) error
+	// DeleteTask перемещает задачу в корзину
+	DeleteTask(id int) error
+	// GetRemovedTask возвращает задачу из корзины или архива
+	GetRemovedTask(id int) (*Task, error)
+	// RestoreTask возвращает задачу из корзины или архива в список задач
+	RestoreTask(id int) error
+	// PurgeTask окончательно удаляет задачу из корзины или архива
+	PurgeTask(id int) error
+	// PurgeDeleted окончательно удаляет задачи, перемещённые в корзину раньше before
+	PurgeDeleted(before time.Time) (int, error)
+	ListTasks(filter TaskFilter) (TaskPage, error)
+	// CompleteTask записывает выполнение в историю и в той же транзакции переносит задачу
+	// с даты taskDate на следующую дату, увеличивая счётчик выполнений, а при нулевой
+	// nextDate — в архив. Если дата задачи уже не taskDate, возвращается ErrTaskChanged.
+	CompleteTask(c Completion, taskDate, nextDate time.Time, repeat string) (int, error)
+	// History возвращает историю выполнения задачи, в том числе удалённой
+	History(taskID int) ([]Completion, error)
+	// Exceptions возвращает исключения для повторений задачи
+	Exceptions(taskID int) ([]Exception, error)
+	// SaveException добавляет исключение или заменяет существующее для той же даты повторения.
+	// Ненулевая nextDate в той же транзакции переносит задачу на эту дату с правилом repeat.
+	SaveException(e Exception, nextDate time.Time, repeat string) (int, error)
+	// ExceptionTaskID возвращает идентификатор задачи, к которой относится исключение
+	ExceptionTaskID(id int) (int, error)
+	DeleteException(id int) error
+	// FullTextSearch сообщает, ищет ли хранилище по словам с сортировкой по релевантности
+	FullTextSearch() bool
+}
+
+// ListRoles определяет роль пользователя в общем списке задач
+type ListRoles interface {
+	// ListRole возвращает роль пользователя в общем списке или пустую строку
+	ListRole(listID, userID int) (string, error)
+}
+
+// ListStore — хранилище общих списков задач и их участников. Списки хранятся только в базе
+// данных: MemoryStore не реализует ListStore, и обработчики списков с ним не подключаются.
+type ListStore interface {
+	ListRoles
+	CreateList(name string, ownerID int) (int, error)
+	GetList(id int) (*List, error)
+	// Lists возвращает списки пользователя с его ролью, при all — все списки
+	Lists(userID int, all bool) ([]List, error)
+	RenameList(id int, name string) error
+	DeleteList(id int) error
+	ListMembers(listID int) ([]ListMember, error)
+	// SetListMember добавляет в список пользователя с логином login или меняет его роль
+	// и возвращает идентификатор пользователя
+	SetListMember(listID int, login, role string) (int, error)
+	RemoveListMember(listID, userID int) error
+}
+
+// Store — хранилище задач и общих списков в базе данных
+type Store interface {
+	TaskStore
+	ListStore
+}
+
+// TaskFilter — условия выборки задач для списка
+type TaskFilter struct {
+	// UserID — пользователь, который видит свои задачи и задачи своих общих списков;
+	// при All видны задачи всех пользователей
+	UserID int
+	All    bool
+	// State — активные задачи (TasksActive), корзина (TasksDeleted) или архив (TasksArchived)
+	State string
+	// Date — задачи на указанную дату с учётом переносов повторений
+	Date time.Time
+	// Search — задачи, в заголовке или комментарии которых есть искомые слова
+	Search     string
+	ListID     *int
+	AssigneeID *int
+	// From и To — диапазон дат включительно, нулевое значение — без ограничения
+	From time.Time
+	To   time.Time
+	// Repeating — только повторяющиеся или только одноразовые задачи
+	Repeating *bool
+	// Sort — поле сортировки: date, title, id, deleted и archived (время перемещения
+	// в корзину и архив) или relevance (только при полнотекстовом поиске активных задач).
+	// Равные значения упорядочиваются по id; Desc — по убыванию
+	Sort string
+	Desc bool
+	// After — задача, после которой начинается страница
+	After *TaskCursor
+	Limit int
+	// CountTotal — посчитать количество задач, подходящих под условия, без учёта страниц
+	CountTotal bool
+}
+
+// TaskCursor — значение поля сортировки и идентификатор задачи, на которой закончилась страница
+type TaskCursor struct {
+	Key interface{}
+	ID  int
+}
+
+// TaskPage — страница списка задач
+type TaskPage struct {
+	Tasks []ListedTask
+	Total int
+	// More — за страницей есть ещё задачи
+	More bool
+}
+
+// ListedTask — задача в списке с датой, заголовком и комментарием текущего повторения
+// из исключений, фрагментом с найденными словами и значением поля сортировки для курсора
+type ListedTask struct {
+	Task
+	Snippet string
+	SortKey interface{}
+}
+
+var (
+	_ Store     = (*SQLiteStore)(nil)
+	_ Store     = (*PostgresStore)(nil)
+	_ TaskStore = (*MemoryStore)(nil)
+)
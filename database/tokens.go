@@ -0,0 +1,119 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// tokensSchema — персональные токены API; хранятся только хэши токенов
+const tokensSchema = `
+	CREATE TABLE IF NOT EXISTS api_tokens (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL,
+		name TEXT NOT NULL,
+		scope TEXT NOT NULL,
+		token_hash TEXT NOT NULL UNIQUE,
+		created_at TEXT NOT NULL,
+		last_used_at TEXT NOT NULL DEFAULT ''
+	);
+	CREATE INDEX IF NOT EXISTS idx_api_tokens_user ON api_tokens (user_id);
+`
+
+// ErrAPITokenNotFound возвращается для неизвестного или отозванного токена
+var ErrAPITokenNotFound = errors.New("Токен не найден")
+
+type APIToken struct {
+	ID        int
+	UserID    int
+	Name      string
+	Scope     string
+	CreatedAt time.Time
+	// LastUsedAt — время последнего запроса с токеном, нулевое значение — токен не использовался
+	LastUsedAt time.Time
+}
+
+// CreateAPIToken сохраняет хэш нового токена и возвращает идентификатор токена
+func CreateAPIToken(db *DB, token APIToken, hash string) (int, error) {
+	query := `INSERT INTO api_tokens (user_id, name, scope, token_hash, created_at) VALUES (?, ?, ?, ?, ?) RETURNING id`
+	var id int
+	err := db.QueryRow(query, token.UserID, token.Name, token.Scope, hash,
+		token.CreatedAt.UTC().Format(time.RFC3339)).Scan(&id)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при создании токена: %w", err)
+	}
+	return id, nil
+}
+
+// GetAPITokens возвращает токены пользователя в порядке создания
+func GetAPITokens(db *DB, userID int) ([]APIToken, error) {
+	query := `SELECT id, user_id, name, scope, created_at, last_used_at FROM api_tokens WHERE user_id = ? ORDER BY id`
+	rows, err := db.Query(query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении токенов: %w", err)
+	}
+	defer rows.Close()
+
+	var tokens []APIToken
+	for rows.Next() {
+		token, err := scanAPIToken(rows)
+		if err != nil {
+			return nil, err
+		}
+		tokens = append(tokens, *token)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ошибка при получении токенов: %w", err)
+	}
+	return tokens, nil
+}
+
+// GetAPITokenByHash ищет токен по хэшу
+func GetAPITokenByHash(db *DB, hash string) (*APIToken, error) {
+	query := `SELECT id, user_id, name, scope, created_at, last_used_at FROM api_tokens WHERE token_hash = ?`
+	token, err := scanAPIToken(db.QueryRow(query, hash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrAPITokenNotFound
+	}
+	return token, err
+}
+
+func scanAPIToken(row interface{ Scan(...any) error }) (*APIToken, error) {
+	var token APIToken
+	var createdAt, lastUsedAt string
+	err := row.Scan(&token.ID, &token.UserID, &token.Name, &token.Scope, &createdAt, &lastUsedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при чтении токена: %w", err)
+	}
+	token.CreatedAt, _ = time.Parse(time.RFC3339, createdAt)
+	if lastUsedAt != "" {
+		token.LastUsedAt, _ = time.Parse(time.RFC3339, lastUsedAt)
+	}
+	return &token, nil
+}
+
+// TouchAPIToken запоминает время последнего использования токена
+func TouchAPIToken(db *DB, id int, now time.Time) error {
+	_, err := db.Exec(`UPDATE api_tokens SET last_used_at = ? WHERE id = ?`, now.UTC().Format(time.RFC3339), id)
+	return err
+}
+
+// DeleteAPIToken отзывает токен пользователя
+func DeleteAPIToken(db *DB, id, userID int) error {
+	result, err := db.Exec(`DELETE FROM api_tokens WHERE id = ? AND user_id = ?`, id, userID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении токена: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrAPITokenNotFound
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// Задачи для выборки: активные, перемещённые в корзину и в архив
+const (
+	TasksActive   = ""
+	TasksDeleted  = "deleted"
+	TasksArchived = "archived"
+)
+
+// taskStates — условия SQL для выборки задач в каждом состоянии
+var taskStates = map[string]string{
+	TasksActive:   "s.deleted_at = '' AND s.archived_at = ''",
+	TasksDeleted:  "s.deleted_at != ''",
+	TasksArchived: "s.archived_at != '' AND s.deleted_at = ''",
+}
+
+// removeTask отмечает активную задачу временем перемещения в корзину или архив (column)
+func removeTask(db execer, column string, taskID int, now time.Time) error {
+	query := `UPDATE scheduler SET ` + column + ` = ? WHERE id = ? AND ` + activeTask
+	result, err := db.Exec(query, now.UTC().Format(time.RFC3339), taskID)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении задачи: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// GetRemovedTask возвращает задачу из корзины или архива
+func GetRemovedTask(db *DB, taskID int) (*Task, error) {
+	return getTask(db, "(deleted_at != '' OR archived_at != '')", taskID)
+}
+
+// RestoreTask возвращает задачу из корзины или архива в список задач
+func RestoreTask(db *DB, taskID int) error {
+	query := `UPDATE scheduler SET deleted_at = '', archived_at = '' WHERE id = ? AND (deleted_at != '' OR archived_at != '')`
+	result, err := db.Exec(query, taskID)
+	if err != nil {
+		return fmt.Errorf("ошибка при восстановлении задачи: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrTaskNotFound
+	}
+	return nil
+}
+
+// PurgeTask окончательно удаляет задачу из корзины или архива вместе с исключениями.
+// История выполнения задачи сохраняется.
+func PurgeTask(db *DB, taskID int) error {
+	purged, err := purgeTasks(db, "id = ? AND (deleted_at != '' OR archived_at != '')", taskID)
+	if err == nil && purged == 0 {
+		return ErrTaskNotFound
+	}
+	return err
+}
+
+// PurgeDeletedTasks окончательно удаляет задачи, перемещённые в корзину раньше before,
+// и возвращает их количество
+func PurgeDeletedTasks(db *DB, before time.Time) (int, error) {
+	return purgeTasks(db, "deleted_at != '' AND deleted_at < ?", before.UTC().Format(time.RFC3339))
+}
+
+func purgeTasks(db *DB, condition string, arg interface{}) (int, error) {
+	var purged int64
+	err := inTx(db, func(tx *Tx) error {
+		_, err := tx.Exec(`DELETE FROM exceptions WHERE task_id IN (SELECT id FROM scheduler WHERE `+condition+`)`, arg)
+		if err != nil {
+			return fmt.Errorf("ошибка при удалении исключений задачи: %w", err)
+		}
+		result, err := tx.Exec(`DELETE FROM scheduler WHERE `+condition, arg)
+		if err != nil {
+			return fmt.Errorf("ошибка при удалении задачи: %w", err)
+		}
+		purged, err = result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("ошибка при получении количества удаленных строк: %w", err)
+		}
+		return nil
+	})
+	return int(purged), err
+}
+
+// removed сообщает, что задача перемещена в корзину или архив
+func (t Task) removed() bool {
+	return !t.DeletedAt.IsZero() || !t.ArchivedAt.IsZero()
+}
+
+// parseOptionalTime разбирает время в формате RFC 3339, пустая строка — нулевое время
+func parseOptionalTime(value string) time.Time {
+	t, _ := time.Parse(time.RFC3339, value)
+	return t
+}
@@ -0,0 +1,88 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// usersSchema — учётные записи пользователей и владелец задачи в таблице scheduler
+const usersSchema = `
+	CREATE TABLE IF NOT EXISTS users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		login TEXT NOT NULL UNIQUE,
+		password_hash TEXT NOT NULL,
+		role TEXT NOT NULL DEFAULT 'user'
+	);
+	CREATE INDEX IF NOT EXISTS idx_owner ON scheduler (owner_id);
+`
+
+// ErrUserExists возвращается при регистрации с занятым логином
+var ErrUserExists = errors.New("Пользователь с таким логином уже существует")
+
+// ErrUserNotFound возвращается, если пользователя нет в базе
+var ErrUserNotFound = errors.New("Пользователь не найден")
+
+type User struct {
+	ID           int
+	Login        string
+	PasswordHash string
+	Role         string
+}
+
+// CreateUser добавляет пользователя с ролью user. Роль администратора назначается
+// только командой role, регистрация через API её не даёт.
+func CreateUser(db *DB, login, passwordHash string) (*User, error) {
+	query := `INSERT INTO users (login, password_hash, role) VALUES (?, ?, 'user') RETURNING id, role`
+
+	user := User{Login: login, PasswordHash: passwordHash}
+	err := db.QueryRow(query, login, passwordHash).Scan(&user.ID, &user.Role)
+	if err != nil {
+		var sqliteErr sqlite3.Error
+		if errors.As(err, &sqliteErr) && sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique || uniqueViolation(err) {
+			return nil, ErrUserExists
+		}
+		return nil, fmt.Errorf("ошибка при создании пользователя: %w", err)
+	}
+	return &user, nil
+}
+
+// GetUser возвращает пользователя по идентификатору
+func GetUser(db *DB, id int) (*User, error) {
+	return getUser(db, "SELECT id, login, password_hash, role FROM users WHERE id = ?", id)
+}
+
+// GetUserByLogin возвращает пользователя по логину
+func GetUserByLogin(db *DB, login string) (*User, error) {
+	return getUser(db, "SELECT id, login, password_hash, role FROM users WHERE login = ?", login)
+}
+
+func getUser(db *DB, query string, arg interface{}) (*User, error) {
+	var user User
+	err := db.QueryRow(query, arg).Scan(&user.ID, &user.Login, &user.PasswordHash, &user.Role)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("ошибка при получении пользователя: %w", err)
+	}
+	return &user, nil
+}
+
+// SetUserRole назначает роль пользователю с указанным логином
+func SetUserRole(db *DB, login, role string) error {
+	result, err := db.Exec(`UPDATE users SET role = ? WHERE login = ?`, role, login)
+	if err != nil {
+		return fmt.Errorf("ошибка при изменении роли пользователя: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("ошибка при получении количества обновленных строк: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
@@ -0,0 +1,234 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"go_final_project/auth"
+	"go_final_project/database"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// minPasswordLength — минимальная длина пароля пользователя
+const minPasswordLength = 6
+
+type credentials struct {
+	Login    string `json:"login"`
+	Password string `json:"password"`
+}
+
+// SigninHandler выдаёт токен пользователю по логину и паролю.
+// Запрос без логина проверяет общий пароль из TODO_PASSWORD и выдаёт токен администратора.
+func SigninHandler(db *database.DB, password string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var request credentials
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+			return
+		}
+
+		if password == "" {
+			writeJSONError(w, "Аутентификация не настроена", http.StatusBadRequest)
+			return
+		}
+
+		var user auth.User
+		var hash string
+		if request.Login == "" {
+			if subtle.ConstantTimeCompare([]byte(request.Password), []byte(password)) != 1 {
+				writeJSONError(w, "Неверный пароль", http.StatusUnauthorized)
+				return
+			}
+			user = auth.User{ID: 0, Role: auth.RoleAdmin}
+			hash = auth.PasswordHash(password)
+		} else {
+			account, err := database.GetUserByLogin(db, request.Login)
+			if err != nil && !errors.Is(err, database.ErrUserNotFound) {
+				writeJSONError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			if account == nil || !auth.CheckPassword(account.PasswordHash, request.Password) {
+				writeJSONError(w, "Неверный логин или пароль", http.StatusUnauthorized)
+				return
+			}
+			user = auth.User{ID: account.ID, Role: account.Role}
+			hash = auth.PasswordHash(account.PasswordHash)
+		}
+
+		writeToken(w, password, user, hash)
+	}
+}
+
+// SignupHandler регистрирует пользователя и сразу выдаёт ему токен
+func SignupHandler(db *database.DB, password string) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var request credentials
+		if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+			http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+			return
+		}
+
+		if password == "" {
+			writeJSONError(w, "Аутентификация не настроена", http.StatusBadRequest)
+			return
+		}
+		request.Login = strings.TrimSpace(request.Login)
+		if request.Login == "" {
+			http.Error(w, `{"error":"Не указан логин"}`, http.StatusBadRequest)
+			return
+		}
+		if utf8.RuneCountInString(request.Password) < minPasswordLength {
+			writeJSONError(w, "Пароль должен содержать не менее 6 символов", http.StatusBadRequest)
+			return
+		}
+
+		passwordHash, err := auth.HashPassword(request.Password)
+		if err != nil {
+			writeJSONError(w, "Ошибка при сохранении пароля", http.StatusInternalServerError)
+			return
+		}
+		account, err := database.CreateUser(db, request.Login, passwordHash)
+		if errors.Is(err, database.ErrUserExists) {
+			writeJSONError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		writeToken(w, password, auth.User{ID: account.ID, Role: account.Role}, auth.PasswordHash(account.PasswordHash))
+	}
+}
+
+// writeToken выдаёт токен пользователю вместе с его ролью
+func writeToken(w http.ResponseWriter, secret string, user auth.User, hash string) {
+	token, err := auth.NewToken(secret, user, hash, time.Now())
+	if err != nil {
+		writeJSONError(w, "Ошибка при создании токена", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"token": token, "role": user.Role})
+}
+
+// Auth возвращает middleware, пропускающее к API только запросы с действительным токеном
+// в куке token или в заголовке Authorization и передающее пользователя в контексте запроса.
+// Персональные токены API с областью read допускают только чтение. Без пароля
+// аутентификация отключена и все запросы выполняются с правами администратора.
+func Auth(db *database.DB, password string) func(next http.HandlerFunc) http.HandlerFunc {
+	return func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			user, err := authenticate(db, password, r)
+			if err != nil {
+				writeJSONError(w, "Требуется аутентификация", http.StatusUnauthorized)
+				return
+			}
+			if user.Scope == auth.ScopeRead && r.Method != http.MethodGet && r.Method != http.MethodHead {
+				writeJSONError(w, "Токен позволяет только чтение", http.StatusForbidden)
+				return
+			}
+			next(w, r.WithContext(auth.WithUser(r.Context(), user)))
+		}
+	}
+}
+
+// AuthPage перенаправляет на страницу входа запросы главной страницы без действительного токена
+func AuthPage(db *database.DB, password string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isIndex := r.URL.Path == "/" || r.URL.Path == "/index.html"
+		if isIndex {
+			if _, err := authenticate(db, password, r); err != nil {
+				http.Redirect(w, r, "/login.html", http.StatusFound)
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate определяет пользователя по токену запроса. Роль берётся из базы,
+// чтобы её изменение действовало без повторного входа.
+func authenticate(db *database.DB, password string, r *http.Request) (auth.User, error) {
+	if password == "" {
+		return auth.User{ID: 0, Role: auth.RoleAdmin}, nil
+	}
+
+	token := requestToken(r)
+	if strings.HasPrefix(token, auth.APITokenPrefix) {
+		return authenticateAPIToken(db, token)
+	}
+
+	claims, err := auth.ParseToken(password, token)
+	if err != nil {
+		return auth.User{}, err
+	}
+	if claims.UserID == 0 {
+		if claims.Hash != auth.PasswordHash(password) {
+			return auth.User{}, auth.ErrInvalidToken
+		}
+		return auth.User{ID: 0, Role: auth.RoleAdmin}, nil
+	}
+
+	account, err := database.GetUser(db, claims.UserID)
+	if err != nil {
+		return auth.User{}, err
+	}
+	if claims.Hash != auth.PasswordHash(account.PasswordHash) {
+		return auth.User{}, auth.ErrInvalidToken
+	}
+	return auth.User{ID: account.ID, Role: account.Role}, nil
+}
+
+// authenticateAPIToken определяет пользователя по персональному токену API.
+// Права администратора дают только токены с областью admin.
+func authenticateAPIToken(db *database.DB, token string) (auth.User, error) {
+	apiToken, err := database.GetAPITokenByHash(db, auth.APITokenHash(token))
+	if err != nil {
+		return auth.User{}, auth.ErrInvalidToken
+	}
+
+	user := auth.User{ID: apiToken.UserID, Role: auth.RoleAdmin, Scope: apiToken.Scope}
+	if apiToken.UserID != 0 {
+		account, err := database.GetUser(db, apiToken.UserID)
+		if err != nil {
+			return auth.User{}, err
+		}
+		user.Role = account.Role
+	}
+	if apiToken.Scope != auth.ScopeAdmin {
+		user.Role = auth.RoleUser
+	}
+
+	if err := database.TouchAPIToken(db, apiToken.ID, time.Now()); err != nil {
+		log.Printf("Ошибка при обновлении времени использования токена: %v", err)
+	}
+	return user, nil
+}
+
+// requestToken возвращает токен из куки token или заголовка "Authorization: Bearer <токен>"
+func requestToken(r *http.Request) string {
+	if cookie, err := r.Cookie("token"); err == nil {
+		return cookie.Value
+	}
+	header := r.Header.Get("Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return token
+	}
+	return header
+}
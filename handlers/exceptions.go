@@ -0,0 +1,230 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"go_final_project/database"
+	"go_final_project/models"
+	"go_final_project/utils"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// ExceptionsHandler управляет исключениями для отдельных повторений задачи:
+// GET возвращает исключения задачи, POST добавляет или заменяет исключение,
+// DELETE удаляет его
+func ExceptionsHandler(store database.TaskStore) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetExceptions(w, r, store)
+		case http.MethodPost:
+			handlePostException(w, r, store)
+		case http.MethodDelete:
+			handleDeleteException(w, r, store)
+		default:
+			http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func handleGetExceptions(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		http.Error(w, `{"error":"Не указан идентификатор задачи"}`, http.StatusBadRequest)
+		return
+	}
+	taskID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор задачи должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+	if _, err := userTask(r, store, idStr, false); err != nil {
+		writeTaskError(w, err)
+		return
+	}
+
+	exceptions, err := store.Exceptions(taskID)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := []models.Exception{}
+	for _, e := range exceptions {
+		exception := models.Exception{
+			ID:      strconv.Itoa(e.ID),
+			TaskID:  strconv.Itoa(e.TaskID),
+			Date:    e.Date.Format("20060102"),
+			Skip:    e.Skip,
+			Title:   e.Title,
+			Comment: e.Comment,
+		}
+		if !e.NewDate.IsZero() {
+			exception.NewDate = e.NewDate.Format("20060102")
+		}
+		result = append(result, exception)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"exceptions": result})
+}
+
+func handlePostException(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
+	var exception models.Exception
+	if err := json.NewDecoder(r.Body).Decode(&exception); err != nil {
+		http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+		return
+	}
+
+	if exception.TaskID == "" {
+		http.Error(w, `{"error":"Не указан идентификатор задачи"}`, http.StatusBadRequest)
+		return
+	}
+	task, err := userTask(r, store, exception.TaskID, true)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	if task.Repeat == "" {
+		writeJSONError(w, "Исключения можно добавлять только для повторяющихся задач", http.StatusBadRequest)
+		return
+	}
+
+	date, err := time.Parse("20060102", exception.Date)
+	if err != nil {
+		http.Error(w, `{"error":"Дата представлена в неверном формате"}`, http.StatusBadRequest)
+		return
+	}
+	if !utils.IsOccurrence(date, task.Date, task.Repeat) {
+		writeJSONError(w, "На указанную дату не выпадает повторение задачи", http.StatusBadRequest)
+		return
+	}
+
+	e := database.Exception{
+		TaskID:  task.ID,
+		Date:    date,
+		Skip:    exception.Skip,
+		Title:   exception.Title,
+		Comment: exception.Comment,
+	}
+	if exception.NewDate != "" {
+		e.NewDate, err = time.Parse("20060102", exception.NewDate)
+		if err != nil {
+			http.Error(w, `{"error":"Дата переноса представлена в неверном формате"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	override := !e.NewDate.IsZero() || e.Title != "" || e.Comment != ""
+	if e.Skip == override {
+		writeJSONError(w, "Укажите либо отмену повторения, либо новую дату, заголовок или комментарий", http.StatusBadRequest)
+		return
+	}
+
+	// Отмена текущего повторения сразу переносит задачу на следующее
+	var nextDate time.Time
+	if e.Skip && date.Equal(task.Date) {
+		exceptions, err := store.Exceptions(task.ID)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		exceptions = append(exceptions, e)
+		nextDate, err = utils.NextDateExcluding(task.Date, task.Date, task.Repeat, skippedDates(exceptions))
+		if errors.Is(err, utils.ErrRuleExhausted) || (err == nil && afterRepeatUntil(task, nextDate)) {
+			writeJSONError(w, "Это последнее повторение задачи, удалите задачу вместо его отмены", http.StatusBadRequest)
+			return
+		}
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	repeat := task.Repeat
+	if !nextDate.IsZero() {
+		repeat = utils.AdvanceRepeat(task.Repeat, task.Date, nextDate)
+	}
+	id, err := store.SaveException(e, nextDate, repeat)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": strconv.Itoa(id)})
+}
+
+func handleDeleteException(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		http.Error(w, `{"error":"Не указан идентификатор исключения"}`, http.StatusBadRequest)
+		return
+	}
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор исключения должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+
+	// Исключение чужой задачи для пользователя не отличается от несуществующего
+	taskID, err := store.ExceptionTaskID(id)
+	if err == nil {
+		if _, err := userTask(r, store, strconv.Itoa(taskID), true); err != nil {
+			if errors.Is(err, errReadOnly) {
+				writeJSONError(w, err.Error(), http.StatusForbidden)
+			} else {
+				http.Error(w, `{"error":"Исключение не найдено"}`, http.StatusNotFound)
+			}
+			return
+		}
+		err = store.DeleteException(id)
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrExceptionNotFound) {
+			http.Error(w, `{"error":"Исключение не найдено"}`, http.StatusNotFound)
+		} else {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte("{}"))
+}
+
+// skippedDates возвращает проверку, отменено ли повторение задачи в указанный день
+func skippedDates(exceptions []database.Exception) func(time.Time) bool {
+	skipped := make(map[string]bool)
+	for _, e := range exceptions {
+		if e.Skip {
+			skipped[e.Date.Format("20060102")] = true
+		}
+	}
+	return func(day time.Time) bool {
+		return skipped[day.Format("20060102")]
+	}
+}
+
+// withOverride подставляет в задачу перенесённую дату, заголовок и комментарий
+// текущего повторения из исключения
+func withOverride(task database.Task, exceptions []database.Exception) database.Task {
+	for _, e := range exceptions {
+		if e.Skip || !e.Date.Equal(task.Date) {
+			continue
+		}
+		if !e.NewDate.IsZero() {
+			task.Date = e.NewDate
+		}
+		if e.Title != "" {
+			task.Title = e.Title
+		}
+		if e.Comment != "" {
+			task.Comment = e.Comment
+		}
+		break
+	}
+	return task
+}
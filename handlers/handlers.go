@@ -1,11 +1,13 @@
 package handlers
 
 import (
-	"database/sql"
 	"encoding/json"
+	"errors"
+	"go_final_project/auth"
 	"go_final_project/database"
 	"go_final_project/models"
 	"go_final_project/utils"
+	"io"
 	"net/http"
 	"strconv"
 	"strings"
@@ -20,48 +22,208 @@ func NextDateHandler(w http.ResponseWriter, r *http.Request) {
 	// Парсинг дат
 	now, err := time.Parse("20060102", nowStr)
 	if err != nil {
-		http.Error(w, "Неверный формат 'now'", http.StatusBadRequest)
+		writeJSONError(w, "Неверный формат 'now'", http.StatusBadRequest)
 		return
 	}
 
 	date, err := time.Parse("20060102", dateStr)
 	if err != nil {
-		http.Error(w, "Неверный формат 'date'", http.StatusBadRequest)
+		writeJSONError(w, "Неверный формат 'date'", http.StatusBadRequest)
 		return
 	}
 
 	nextDate, err := utils.NextDate(now, date, repeatStr)
+	if errors.Is(err, utils.ErrRuleExhausted) {
+		// Правило корректно, но следующей даты нет — возвращаем пустой ответ
+		return
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Write([]byte(nextDate.Format("20060102")))
+}
+
+// maxOccurrences ограничивает количество дат в ответе /api/occurrences
+const maxOccurrences = 500
+
+// OccurrencesHandler возвращает следующие даты выполнения по правилу повторения
+// и его описание, чтобы правило можно было проверить до сохранения задачи
+func OccurrencesHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	query := r.URL.Query()
+	repeatStr := query.Get("repeat")
+
+	now := utils.Today(time.Now(), utils.DefaultLocation())
+	if nowStr := query.Get("now"); nowStr != "" {
+		var err error
+		now, err = time.Parse("20060102", nowStr)
+		if err != nil {
+			writeJSONError(w, "Неверный формат 'now'", http.StatusBadRequest)
+			return
+		}
+	}
 
+	date := now
+	if dateStr := query.Get("date"); dateStr != "" {
+		var err error
+		date, err = time.Parse("20060102", dateStr)
+		if err != nil {
+			writeJSONError(w, "Неверный формат 'date'", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var until time.Time
+	if untilStr := query.Get("until"); untilStr != "" {
+		var err error
+		until, err = time.Parse("20060102", untilStr)
+		if err != nil {
+			writeJSONError(w, "Неверный формат 'until'", http.StatusBadRequest)
+			return
+		}
+	}
+
+	// Без count и until возвращаем 10 дат, с until — все даты до него в пределах лимита
+	count := 10
+	if !until.IsZero() {
+		count = maxOccurrences
+	}
+	if countStr := query.Get("count"); countStr != "" {
+		var err error
+		count, err = strconv.Atoi(countStr)
+		if err != nil || count < 1 || count > maxOccurrences {
+			writeJSONError(w, "Параметр 'count' должен быть числом от 1 до "+strconv.Itoa(maxOccurrences), http.StatusBadRequest)
+			return
+		}
+	}
+
+	occurrences, err := utils.Occurrences(now, date, repeatStr, count, until)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	description, err := utils.DescribeRepeat(repeatStr, requestLang(r))
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	response := nextDate.Format("20060102")
-	if response == "00010101" {
-		response = ""
+	dates := make([]string, 0, len(occurrences))
+	for _, occurrence := range occurrences {
+		dates = append(dates, occurrence.Format("20060102"))
+	}
+
+	response := map[string]interface{}{
+		"dates":       dates,
+		"description": description,
 	}
-	w.Write([]byte(response))
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
 }
 
-func TaskHandler(db *sql.DB) func(w http.ResponseWriter, r *http.Request) {
+// requestLang возвращает язык описаний правил повторения: параметр lang
+// или первый язык из заголовка Accept-Language
+func requestLang(r *http.Request) string {
+	if lang := r.URL.Query().Get("lang"); lang != "" {
+		return lang
+	}
+	return strings.TrimSpace(strings.Split(r.Header.Get("Accept-Language"), ",")[0])
+}
+
+// describeRepeat возвращает описание правила повторения задачи или пустую строку,
+// если задача одноразовая или правило не удаётся разобрать
+func describeRepeat(repeat, lang string) string {
+	if repeat == "" {
+		return ""
+	}
+	text, err := utils.DescribeRepeat(repeat, lang)
+	if err != nil {
+		return ""
+	}
+	return text
+}
+
+// writeJSONError отправляет ошибку в формате {"error":"..."}
+func writeJSONError(w http.ResponseWriter, message string, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": message})
+}
+
+// errReadOnly возвращается при попытке наблюдателя общего списка изменить задачу
+var errReadOnly = errors.New("Недостаточно прав для изменения задачи")
+
+// userTask возвращает задачу, если она доступна пользователю запроса. Задачи общих списков
+// могут читать все участники, а изменять — владелец списка и редакторы (write).
+// Недоступная задача для пользователя не отличается от несуществующей.
+func userTask(r *http.Request, store database.TaskStore, id string, write bool) (*database.Task, error) {
+	taskID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, database.ErrTaskNotFound
+	}
+	task, err := store.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+	return taskAccess(r, store, task, write)
+}
+
+// taskAccess возвращает задачу, если пользователь запроса может её читать или изменять (write)
+func taskAccess(r *http.Request, store database.TaskStore, task *database.Task, write bool) (*database.Task, error) {
+	user := auth.FromContext(r.Context())
+	if user.CanAccess(task.OwnerID) {
+		return task, nil
+	}
+	if task.ListID != 0 {
+		role, err := store.ListRole(task.ListID, user.ID)
+		if err != nil && !errors.Is(err, database.ErrListNotFound) {
+			return nil, err
+		}
+		switch {
+		case role == database.ListRoleOwner || role == database.ListRoleEditor:
+			return task, nil
+		case role == database.ListRoleViewer && !write:
+			return task, nil
+		case role == database.ListRoleViewer:
+			return nil, errReadOnly
+		}
+	}
+	return nil, database.ErrTaskNotFound
+}
+
+// writeTaskError отвечает на ошибку получения задачи через userTask
+func writeTaskError(w http.ResponseWriter, err error) {
+	if errors.Is(err, errReadOnly) {
+		writeJSONError(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	http.Error(w, `{"error":"Задача не найдена"}`, http.StatusNotFound)
+}
+
+func TaskHandler(store database.TaskStore) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		switch r.Method {
 		case http.MethodPost:
-			handlePostTask(w, r, db)
+			handlePostTask(w, r, store)
 		case http.MethodGet:
-			handleGetTask(w, r, db)
+			handleGetTask(w, r, store)
 		case http.MethodPut:
-			handlePutTask(w, r, db)
+			handlePutTask(w, r, store)
 		case http.MethodDelete:
-			handleDeleteTask(w, r, db)
+			handleDeleteTask(w, r, store)
 		default:
 			http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
 		}
 	}
 }
 
-func handlePostTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+func handlePostTask(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
 	var task models.Task
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
 		http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
@@ -72,16 +234,37 @@ func handlePostTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		http.Error(w, `{"error":"Не указан заголовок задачи"}`, http.StatusBadRequest)
 		return
 	}
-	if !isValidRepeatFormat(task.Repeat) {
-		http.Error(w, `{"error":"Неверный формат правила повторения"}`, http.StatusBadRequest)
+	loc, err := taskLocation(task)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 	now := time.Now()
+	today := utils.Today(now, loc)
+	if task.Text != "" {
+		if err := applyPhrase(&task, today); err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+	if err := validateRepeat(task.Repeat); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	until, count, err := parseRepeatLimit(task)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mode, err := parseRepeatMode(task)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
 	var taskDate time.Time
-	var err error
-	if task.Date == "" || task.Date == now.Format("20060102") {
-		taskDate = now
+	if task.Date == "" || task.Date == today.Format("20060102") {
+		taskDate = today
 	} else {
 		taskDate, err = time.Parse("20060102", task.Date)
 		if err != nil {
@@ -90,20 +273,43 @@ func handlePostTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		}
 	}
 
-	if taskDate.Before(now) {
+	if taskInPast(taskDate, task.Time, loc, now) {
 		if task.Repeat == "" {
-			taskDate = now
+			taskDate = today
 		} else {
-			nextDate, err := utils.NextDate(now, taskDate, task.Repeat)
+			nextDate, err := utils.NextDate(today, taskDate, task.Repeat)
 			if err != nil {
-				http.Error(w, `{"error":"Неверный формат правила повторения"}`, http.StatusBadRequest)
+				writeJSONError(w, err.Error(), http.StatusBadRequest)
 				return
 			}
+			task.Repeat = utils.AdvanceRepeat(task.Repeat, taskDate, nextDate)
 			taskDate = nextDate
 		}
 	}
+	if !until.IsZero() && taskDate.After(until) {
+		writeJSONError(w, "Дата задачи позже даты окончания повторений", http.StatusBadRequest)
+		return
+	}
+	listID, assigneeID, err := taskAssignment(r, store, task, nil)
+	if err != nil {
+		writeAssignmentError(w, err)
+		return
+	}
 
-	taskID, err := database.InsertTask(db, taskDate, task.Title, task.Comment, task.Repeat)
+	taskID, err := store.CreateTask(database.Task{
+		Date:        taskDate,
+		Title:       task.Title,
+		Comment:     task.Comment,
+		Repeat:      task.Repeat,
+		RepeatUntil: until,
+		RepeatCount: count,
+		Time:        task.Time,
+		Timezone:    task.Timezone,
+		RepeatMode:  mode,
+		OwnerID:     auth.FromContext(r.Context()).ID,
+		ListID:      listID,
+		AssigneeID:  assigneeID,
+	})
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -114,66 +320,292 @@ func handlePostTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	json.NewEncoder(w).Encode(response)
 }
 
-func isValidRepeatFormat(repeat string) bool {
-	// Проверка на "y"
-	if repeat == "y" {
-		return true
+// applyPhrase заполняет дату и правило повторения задачи из фразы в поле text,
+// если они не указаны явно
+func applyPhrase(task *models.Task, now time.Time) error {
+	phrase, err := utils.ParsePhrase(now, task.Text)
+	if err != nil {
+		return err
+	}
+	if task.Date == "" {
+		task.Date = phrase.Date.Format("20060102")
+	}
+	if task.Repeat == "" {
+		task.Repeat = phrase.Repeat
+	}
+	return nil
+}
+
+// ParseHandler разбирает фразу на русском или английском языке
+// в дату задачи и правило повторения
+func ParseHandler(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request struct {
+		Text string `json:"text"`
+		Now  string `json:"now,omitempty"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+		return
 	}
 
-	// Проверка на "d <число>"
-	parts := strings.Split(repeat, " ")
-	if len(parts) == 2 && parts[0] == "d" {
-		if _, err := strconv.Atoi(parts[1]); err == nil {
-			return true
+	now := utils.Today(time.Now(), utils.DefaultLocation())
+	if request.Now != "" {
+		var err error
+		now, err = time.Parse("20060102", request.Now)
+		if err != nil {
+			writeJSONError(w, "Неверный формат 'now'", http.StatusBadRequest)
+			return
 		}
 	}
+
+	phrase, err := utils.ParsePhrase(now, request.Text)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	response := map[string]string{
+		"date":   phrase.Date.Format("20060102"),
+		"repeat": phrase.Repeat,
+	}
+	if text := describeRepeat(phrase.Repeat, requestLang(r)); text != "" {
+		response["repeat_text"] = text
+	}
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+// validateRepeat проверяет правило повторения; пустое правило означает одноразовую задачу
+func validateRepeat(repeat string) error {
 	if repeat == "" {
+		return nil
+	}
+	return utils.ValidateRepeat(repeat)
+}
+
+// parseRepeatLimit разбирает ограничения повторения задачи: дату окончания
+// repeat_until и общее количество выполнений repeat_count
+func parseRepeatLimit(task models.Task) (time.Time, int, error) {
+	var until time.Time
+	var count int
+	if task.RepeatUntil == "" && task.RepeatCount == "" {
+		return until, count, nil
+	}
+	if task.Repeat == "" {
+		return until, count, errors.New("Ограничение повторений указано для задачи без правила повторения")
+	}
+	if task.RepeatUntil != "" {
+		var err error
+		until, err = time.Parse("20060102", task.RepeatUntil)
+		if err != nil {
+			return until, count, errors.New("Дата окончания повторений представлена в неверном формате")
+		}
+	}
+	if task.RepeatCount != "" {
+		var err error
+		count, err = strconv.Atoi(task.RepeatCount)
+		if err != nil || count < 0 {
+			return until, count, errors.New("Количество повторений должно быть неотрицательным числом")
+		}
+	}
+	return until, count, nil
+}
+
+// parseRepeatMode проверяет режим повторения задачи; по умолчанию следующая дата
+// отсчитывается от даты задачи
+func parseRepeatMode(task models.Task) (string, error) {
+	switch task.RepeatMode {
+	case "", models.RepeatModeDue:
+		return models.RepeatModeDue, nil
+	case models.RepeatModeCompletion:
+		return models.RepeatModeCompletion, nil
+	default:
+		return "", errors.New("Неизвестный режим повторения, ожидается \"due\" или \"completion\"")
+	}
+}
+
+// repeatExhausted проверяет, исчерпаны ли ограничения повторения задачи
+// после очередного выполнения, если следующая дата — nextDate
+func repeatExhausted(task *database.Task, nextDate time.Time) bool {
+	if task.RepeatCount > 0 && task.DoneCount+1 >= task.RepeatCount {
 		return true
 	}
-	return false
+	return afterRepeatUntil(task, nextDate)
 }
 
-func GetTasks(db *sql.DB) func(w http.ResponseWriter, r *http.Request) {
+// afterRepeatUntil проверяет, что дата позже даты окончания повторений задачи
+func afterRepeatUntil(task *database.Task, date time.Time) bool {
+	return !task.RepeatUntil.IsZero() && date.After(task.RepeatUntil)
+}
+
+// taskLocation проверяет время выполнения задачи и возвращает её часовой пояс
+func taskLocation(task models.Task) (*time.Location, error) {
+	if task.Time != "" {
+		if _, err := time.Parse("15:04", task.Time); err != nil {
+			return nil, errors.New("Время представлено в неверном формате, ожидается HH:MM")
+		}
+	}
+	return utils.LoadLocation(task.Timezone)
+}
+
+// taskInPast проверяет, прошла ли дата задачи; для задачи со временем выполнения
+// на сегодня учитывается и время в её часовом поясе
+func taskInPast(date time.Time, clock string, loc *time.Location, now time.Time) bool {
+	today := utils.Today(now, loc)
+	if date.Before(today) {
+		return true
+	}
+	if clock == "" || date.After(today) {
+		return false
+	}
+	due, err := utils.DueTime(date, clock, loc)
+	return err == nil && due.Before(now)
+}
+
+// dueTimestamps возвращает момент выполнения задачи в её часовом поясе и в UTC в формате RFC 3339
+func dueTimestamps(date time.Time, clock, timezone string) (string, string) {
+	loc, err := utils.LoadLocation(timezone)
+	if err != nil {
+		return "", ""
+	}
+	due, err := utils.DueTime(date, clock, loc)
+	if err != nil {
+		return "", ""
+	}
+	return due.Format(time.RFC3339), due.UTC().Format(time.RFC3339)
+}
+
+func GetTasks(store database.TaskStore) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodGet {
 			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
 			return
 		}
 
-		rows, err := db.Query("SELECT id, date, title, comment, repeat FROM scheduler ORDER BY date ASC LIMIT 50")
-		if err != nil {
-			http.Error(w, `{"error": "Ошибка выполнения запроса"}`, http.StatusInternalServerError)
-			return
-		}
-		defer rows.Close()
+		lang := requestLang(r)
 
-		var tasks []models.Task
+		// Пользователь видит свои задачи и задачи общих списков, в которых участвует,
+		// администратор — задачи всех пользователей
+		user := auth.FromContext(r.Context())
+		filter := database.TaskFilter{UserID: user.ID, All: user.IsAdmin()}
 
-		for rows.Next() {
-			var task models.Task
+		// Поиск по дате в формате DD.MM.YYYY или по словам заголовка и комментария.
+		// Полнотекстовый поиск упорядочивает задачи по релевантности и выделяет найденные слова.
+		if search := strings.TrimSpace(r.URL.Query().Get("search")); search != "" {
+			if date, err := time.Parse("02.01.2006", search); err == nil {
+				filter.Date = date
+			} else {
+				filter.Search = search
+			}
+		}
+		fullText := filter.Search != "" && store.FullTextSearch()
 
-			if err := rows.Scan(&task.ID, &task.Date, &task.Title, &task.Comment, &task.Repeat); err != nil {
-				http.Error(w, `{"error": "Ошибка чтения данных"}`, http.StatusInternalServerError)
+		if list := r.URL.Query().Get("list"); list != "" {
+			listID, err := strconv.Atoi(list)
+			if err != nil {
+				http.Error(w, `{"error":"Идентификатор списка должен быть числом"}`, http.StatusBadRequest)
+				return
+			}
+			filter.ListID = &listID
+		}
+		// Исполнитель задаётся идентификатором или словом me — текущий пользователь
+		if assignee := r.URL.Query().Get("assignee"); assignee != "" {
+			assigneeID := user.ID
+			if assignee != "me" {
+				var err error
+				assigneeID, err = strconv.Atoi(assignee)
+				if err != nil {
+					http.Error(w, `{"error":"Идентификатор исполнителя должен быть числом"}`, http.StatusBadRequest)
+					return
+				}
+			}
+			filter.AssigneeID = &assigneeID
+		}
+		// Диапазон дат from и to включительно, в формате YYYYMMDD
+		for _, bound := range []struct {
+			param string
+			date  *time.Time
+		}{{"from", &filter.From}, {"to", &filter.To}} {
+			value := r.URL.Query().Get(bound.param)
+			if value == "" {
+				continue
+			}
+			date, err := time.Parse("20060102", value)
+			if err != nil {
+				writeJSONError(w, "Параметр "+bound.param+" должен быть датой в формате YYYYMMDD", http.StatusBadRequest)
 				return
 			}
+			*bound.date = date
+		}
+		switch r.URL.Query().Get("repeating") {
+		case "":
+		case "true", "false":
+			repeating := r.URL.Query().Get("repeating") == "true"
+			filter.Repeating = &repeating
+		default:
+			writeJSONError(w, "Параметр repeating должен быть true или false", http.StatusBadRequest)
+			return
+		}
 
-			tasks = append(tasks, task)
+		field, order, err := parseTasksSort(r, fullText)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.Sort, filter.Desc = field, order == "DESC"
+		filter.Limit, err = parseTasksLimit(r)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
 		}
 
-		if tasks == nil {
-			tasks = []models.Task{}
+		// Следующая страница начинается после задачи из курсора
+		sortName := field + " " + order
+		if value := r.URL.Query().Get("cursor"); value != "" {
+			cursor, err := decodeCursor(value)
+			if err == nil && cursor.Sort != sortName {
+				err = errors.New("Курсор получен для другой сортировки")
+			}
+			if err != nil {
+				writeJSONError(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			filter.After = &database.TaskCursor{Key: cursor.Key, ID: cursor.ID}
 		}
 
-		// Проверка ошибок после завершения перебора
-		if err := rows.Err(); err != nil {
-			http.Error(w, `{"error": "Ошибка при переборе результатов"}`, http.StatusInternalServerError)
+		// Общее количество и курсор следующей страницы возвращаются клиентам, которые
+		// запрашивают страницы явно, остальным — прежний ответ только со списком задач
+		paged := r.URL.Query().Has("limit") || r.URL.Query().Has("cursor")
+		filter.CountTotal = paged
+
+		page, err := store.ListTasks(filter)
+		if err != nil {
+			http.Error(w, `{"error": "Ошибка выполнения запроса"}`, http.StatusInternalServerError)
 			return
 		}
 
+		tasks := []models.Task{}
+		for _, listed := range page.Tasks {
+			tasks = append(tasks, listedTask(listed, lang))
+		}
+
 		// Создание ответа
 
 		w.Header().Set("Content-Type", "application/json")
-		jsonResponse, err := json.Marshal(map[string]interface{}{"tasks": tasks})
+		response := map[string]interface{}{"tasks": tasks}
+		if paged {
+			response["total"] = page.Total
+			if page.More && len(page.Tasks) > 0 {
+				last := page.Tasks[len(page.Tasks)-1]
+				response["next_cursor"] = encodeCursor(taskCursor{Sort: sortName, Key: last.SortKey, ID: last.ID})
+			}
+		}
+		jsonResponse, err := json.Marshal(response)
 		if err != nil {
 			http.Error(w, `{"error": "Ошибка при переборе результатов"}`, http.StatusInternalServerError)
 			return
@@ -183,19 +615,71 @@ func GetTasks(db *sql.DB) func(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-func handleGetTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+// listedTask преобразует задачу из списка в ответ API
+func listedTask(listed database.ListedTask, lang string) models.Task {
+	task := models.Task{
+		ID:         strconv.Itoa(listed.ID),
+		Date:       listed.Date.Format("20060102"),
+		Title:      listed.Title,
+		Comment:    listed.Comment,
+		Repeat:     listed.Repeat,
+		Time:       listed.Time,
+		Timezone:   listed.Timezone,
+		RepeatMode: listed.RepeatMode,
+		Snippet:    listed.Snippet,
+	}
+	if task.Repeat == "" {
+		task.RepeatMode = ""
+	}
+	if !listed.RepeatUntil.IsZero() {
+		task.RepeatUntil = listed.RepeatUntil.Format("20060102")
+	}
+	task.DueLocal, task.DueUTC = dueTimestamps(listed.Date, task.Time, task.Timezone)
+	task.RepeatText = describeRepeat(task.Repeat, lang)
+	if listed.RepeatCount > 0 {
+		task.RepeatCount = strconv.Itoa(listed.RepeatCount)
+	}
+	if listed.DoneCount > 0 {
+		task.DoneCount = strconv.Itoa(listed.DoneCount)
+	}
+	if listed.ListID != 0 {
+		task.ListID = strconv.Itoa(listed.ListID)
+	}
+	if listed.AssigneeID != 0 {
+		task.AssigneeID = strconv.Itoa(listed.AssigneeID)
+	}
+	if !listed.DeletedAt.IsZero() {
+		task.DeletedAt = listed.DeletedAt.Format(time.RFC3339)
+	}
+	if !listed.ArchivedAt.IsZero() {
+		task.ArchivedAt = listed.ArchivedAt.Format(time.RFC3339)
+	}
+	return task
+}
+
+func handleGetTask(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
 	id := r.URL.Query().Get("id")
 	if id == "" {
 		http.Error(w, `{"error":"Не указан идентификатор"}`, http.StatusBadRequest)
 		return
 	}
 
-	task, err := database.GetTaskByID(db, id)
+	task, err := userTask(r, store, id, false)
 	if err != nil {
-		http.Error(w, `{"error":"Задача не найдена"}`, http.StatusNotFound)
+		writeTaskError(w, err)
 		return
 	}
 
+	// Как и в списке задач, показывается текущее повторение с изменениями из исключения
+	if task.Repeat != "" {
+		exceptions, err := store.Exceptions(task.ID)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		*task = withOverride(*task, exceptions)
+	}
+
 	response := map[string]interface{}{
 		"id":      strconv.Itoa(task.ID),
 		"date":    task.Date.Format("20060102"),
@@ -203,11 +687,39 @@ func handleGetTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		"comment": task.Comment,
 		"repeat":  task.Repeat,
 	}
+	if text := describeRepeat(task.Repeat, requestLang(r)); text != "" {
+		response["repeat_text"] = text
+	}
+	if !task.RepeatUntil.IsZero() {
+		response["repeat_until"] = task.RepeatUntil.Format("20060102")
+	}
+	if task.RepeatCount > 0 {
+		response["repeat_count"] = strconv.Itoa(task.RepeatCount)
+	}
+	if task.DoneCount > 0 {
+		response["done_count"] = strconv.Itoa(task.DoneCount)
+	}
+	if task.Repeat != "" {
+		response["repeat_mode"] = task.RepeatMode
+	}
+	if task.Time != "" {
+		response["time"] = task.Time
+	}
+	if task.Timezone != "" {
+		response["timezone"] = task.Timezone
+	}
+	if task.ListID != 0 {
+		response["list_id"] = strconv.Itoa(task.ListID)
+	}
+	if task.AssigneeID != 0 {
+		response["assignee_id"] = strconv.Itoa(task.AssigneeID)
+	}
+	response["due_local"], response["due_utc"] = dueTimestamps(task.Date, task.Time, task.Timezone)
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(response)
 }
 
-func handlePutTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+func handlePutTask(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
 	var task models.Task
 	if err := json.NewDecoder(r.Body).Decode(&task); err != nil {
 		http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
@@ -222,17 +734,49 @@ func handlePutTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		http.Error(w, `{"error":"Не указан заголовок задачи"}`, http.StatusBadRequest)
 		return
 	}
-	if !isValidRepeatFormat(task.Repeat) {
-		http.Error(w, `{"error":"Неверный формат правила повторения"}`, http.StatusBadRequest)
+	if err := validateRepeat(task.Repeat); err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	until, count, err := parseRepeatLimit(task)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	mode, err := parseRepeatMode(task)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	taskID, err := strconv.Atoi(task.ID)
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор задачи должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+	current, err := userTask(r, store, task.ID, true)
+	if err != nil {
+		writeTaskError(w, err)
+		return
+	}
+	listID, assigneeID, err := taskAssignment(r, store, task, current)
+	if err != nil {
+		writeAssignmentError(w, err)
+		return
+	}
+
+	loc, err := taskLocation(task)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	now := time.Now()
+	today := utils.Today(now, loc)
 	var taskDate time.Time
-	var err error
 
-	if task.Date == "" || task.Date == now.Format("20060102") {
-		taskDate = now
+	if task.Date == "" || task.Date == today.Format("20060102") {
+		taskDate = today
 	} else {
 		taskDate, err = time.Parse("20060102", task.Date)
 		if err != nil {
@@ -241,26 +785,44 @@ func handlePutTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		}
 	}
 
-	if taskDate.Before(now) {
+	if taskInPast(taskDate, task.Time, loc, now) {
 		if task.Repeat == "" {
-			taskDate = now
+			taskDate = today
 		} else {
-			nextDate, err := utils.NextDate(now, taskDate, task.Repeat)
+			exceptions, err := store.Exceptions(taskID)
+			if err != nil {
+				writeJSONError(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			nextDate, err := utils.NextDateExcluding(today, taskDate, task.Repeat, skippedDates(exceptions))
 			if err != nil {
-				http.Error(w, `{"error":"Неверный формат правила повторения"}`, http.StatusBadRequest)
+				writeJSONError(w, err.Error(), http.StatusBadRequest)
 				return
 			}
+			task.Repeat = utils.AdvanceRepeat(task.Repeat, taskDate, nextDate)
 			taskDate = nextDate
 		}
 	}
-	taskID, err := strconv.Atoi(task.ID)
-	if err != nil {
-		http.Error(w, `{"error":"Идентификатор задачи должен быть числом"}`, http.StatusBadRequest)
+	if !until.IsZero() && taskDate.After(until) {
+		writeJSONError(w, "Дата задачи позже даты окончания повторений", http.StatusBadRequest)
 		return
 	}
-	err = database.UpdateTask(db, taskID, taskDate, task.Title, task.Comment, task.Repeat)
+	err = store.UpdateTask(database.Task{
+		ID:          taskID,
+		Date:        taskDate,
+		Title:       task.Title,
+		Comment:     task.Comment,
+		Repeat:      task.Repeat,
+		RepeatUntil: until,
+		RepeatCount: count,
+		Time:        task.Time,
+		Timezone:    task.Timezone,
+		RepeatMode:  mode,
+		ListID:      listID,
+		AssigneeID:  assigneeID,
+	})
 	if err != nil {
-		if err.Error() == "Задача не найдена" {
+		if errors.Is(err, database.ErrTaskNotFound) {
 			http.Error(w, `{"error":"Задача не найдена"}`, http.StatusNotFound)
 		} else {
 			http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -274,7 +836,7 @@ func handlePutTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 	w.Write([]byte("{}"))
 }
 
-func HandlePostTaskDone(db *sql.DB) func(w http.ResponseWriter, r *http.Request) {
+func HandlePostTaskDone(store database.TaskStore) func(w http.ResponseWriter, r *http.Request) {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if r.Method != http.MethodPost {
 			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
@@ -293,38 +855,99 @@ func HandlePostTaskDone(db *sql.DB) func(w http.ResponseWriter, r *http.Request)
 			return
 		}
 
-		task, err := database.GetTaskByID(db, idStr)
+		// Необязательная заметка о выполнении передаётся в теле запроса
+		var done struct {
+			Note string `json:"note"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&done); err != nil && !errors.Is(err, io.EOF) {
+			http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+			return
+		}
+
+		task, err := userTask(r, store, idStr, true)
+		if errors.Is(err, errReadOnly) {
+			writeJSONError(w, err.Error(), http.StatusForbidden)
+			return
+		}
 		if err != nil {
 			w.Write([]byte("{}"))
 			return
 		}
 
-		now := time.Now()
+		// Дата «сегодня» определяется в часовом поясе задачи
+		loc, err := utils.LoadLocation(task.Timezone)
+		if err != nil {
+			loc = utils.DefaultLocation()
+		}
+		now := utils.Today(time.Now(), loc)
 		var nextDate time.Time
 
+		// В режиме "completion" следующая дата отсчитывается от дня выполнения
+		base := task.Date
+		if task.RepeatMode == models.RepeatModeCompletion {
+			base = now
+		}
+
+		// В историю записывается текущее повторение с перенесённой датой и заголовком
+		occurrence := *task
 		if task.Repeat != "" {
-			// Рассчитываем следующую дату выполнения
-			nextDate, err = utils.NextDate(now, task.Date, task.Repeat)
+			exceptions, err := store.Exceptions(taskID)
 			if err != nil {
-				http.Error(w, `{"error":"Ошибка при расчете следующей даты"}`, http.StatusInternalServerError)
+				writeJSONError(w, err.Error(), http.StatusInternalServerError)
 				return
 			}
-
-			// Обновляем дату задачи
-			err = database.UpdateTaskDate(db, taskID, nextDate)
-			if err != nil {
-				http.Error(w, `{"error":"Ошибка при обновлении даты задачи"}`, http.StatusInternalServerError)
+			occurrence = withOverride(*task, exceptions)
+			// Рассчитываем следующую дату выполнения, пропуская отменённые повторения
+			nextDate, err = utils.NextDateExcluding(now, base, task.Repeat, skippedDates(exceptions))
+			if errors.Is(err, utils.ErrOutsideCalendar) {
+				writeJSONError(w, err.Error(), http.StatusBadRequest)
 				return
 			}
-		} else {
-			// Если задача одноразовая, удаляем ее
-			err = database.DeleteTask(db, taskID)
-			if err != nil {
-				http.Error(w, `{"error":"Ошибка при удалении задачи"}`, http.StatusInternalServerError)
+			if err != nil && !errors.Is(err, utils.ErrRuleExhausted) {
+				http.Error(w, `{"error":"Ошибка при расчете следующей даты"}`, http.StatusInternalServerError)
 				return
 			}
 		}
 
+		// Задача одноразовая или повторения исчерпаны — нулевая дата переносит её в архив,
+		// иначе обновляются дата, оставшееся количество повторений и счётчик выполнений.
+		// В режиме "completion" каждое выполнение расходует ровно одно повторение COUNT.
+		repeat := task.Repeat
+		left := true
+		if task.RepeatMode == models.RepeatModeCompletion {
+			repeat, left = utils.CountDown(task.Repeat)
+		}
+		switch {
+		case !left || nextDate.IsZero() || repeatExhausted(task, nextDate):
+			nextDate = time.Time{}
+			repeat = task.Repeat
+		case task.RepeatMode != models.RepeatModeCompletion:
+			repeat = utils.AdvanceRepeat(task.Repeat, base, nextDate)
+		}
+
+		// Выполнение записывается в историю в одной транзакции с переносом или архивацией задачи
+		_, err = store.CompleteTask(database.Completion{
+			TaskID:      taskID,
+			OwnerID:     task.OwnerID,
+			Title:       occurrence.Title,
+			Date:        occurrence.Date,
+			CompletedAt: time.Now(),
+			UserID:      auth.FromContext(r.Context()).ID,
+			Note:        done.Note,
+		}, task.Date, nextDate, repeat)
+		if errors.Is(err, database.ErrTaskNotFound) {
+			writeTaskError(w, err)
+			return
+		}
+		if errors.Is(err, database.ErrTaskChanged) {
+			writeJSONError(w, err.Error(), http.StatusConflict)
+			return
+		}
+		if err != nil {
+			http.Error(w, `{"error":"Ошибка при сохранении выполнения задачи"}`, http.StatusInternalServerError)
+			return
+		}
+
 		// Возвращаем пустой JSON
 		w.Header().Set("Content-Type", "application/json")
 		w.WriteHeader(http.StatusOK)
@@ -332,7 +955,7 @@ func HandlePostTaskDone(db *sql.DB) func(w http.ResponseWriter, r *http.Request)
 	}
 }
 
-func handleDeleteTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
+func handleDeleteTask(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
 	idStr := r.URL.Query().Get("id")
 	if idStr == "" {
 		http.Error(w, `{"error":"Не указан идентификатор задачи"}`, http.StatusBadRequest)
@@ -344,10 +967,14 @@ func handleDeleteTask(w http.ResponseWriter, r *http.Request, db *sql.DB) {
 		http.Error(w, `{"error":"Идентификатор задачи должен быть числом"}`, http.StatusBadRequest)
 		return
 	}
+	if _, err := userTask(r, store, idStr, true); err != nil {
+		writeTaskError(w, err)
+		return
+	}
 
-	err = database.DeleteTask(db, taskID)
+	err = store.DeleteTask(taskID)
 	if err != nil {
-		if err.Error() == "Задача не найдена" {
+		if errors.Is(err, database.ErrTaskNotFound) {
 			http.Error(w, `{"error":"Задача не найдена"}`, http.StatusNotFound)
 		} else {
 			http.Error(w, `{"error":"Ошибка при удалении задачи"}`, http.StatusInternalServerError)
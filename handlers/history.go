@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"go_final_project/auth"
+	"go_final_project/database"
+	"go_final_project/models"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// HistoryHandler возвращает историю выполнения задачи, начиная с последнего выполнения.
+// История удалённой задачи доступна её владельцу и администратору.
+func HistoryHandler(store database.TaskStore) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+			return
+		}
+
+		idStr := r.URL.Query().Get("id")
+		if idStr == "" {
+			http.Error(w, `{"error":"Не указан идентификатор задачи"}`, http.StatusBadRequest)
+			return
+		}
+		taskID, err := strconv.Atoi(idStr)
+		if err != nil {
+			http.Error(w, `{"error":"Идентификатор задачи должен быть числом"}`, http.StatusBadRequest)
+			return
+		}
+
+		// Задачи уже нет — права проверяются по владельцу, сохранённому в истории
+		deleted := false
+		if _, err := userTask(r, store, idStr, false); err != nil {
+			if !errors.Is(err, database.ErrTaskNotFound) {
+				writeTaskError(w, err)
+				return
+			}
+			if _, err := store.GetTask(taskID); !errors.Is(err, database.ErrTaskNotFound) {
+				writeTaskError(w, database.ErrTaskNotFound)
+				return
+			}
+			deleted = true
+		}
+
+		history, err := store.History(taskID)
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		user := auth.FromContext(r.Context())
+		result := []models.Completion{}
+		for _, c := range history {
+			if deleted && !user.CanAccess(c.OwnerID) {
+				continue
+			}
+			completion := models.Completion{
+				ID:          strconv.Itoa(c.ID),
+				TaskID:      strconv.Itoa(c.TaskID),
+				Title:       c.Title,
+				Date:        c.Date.Format("20060102"),
+				CompletedAt: c.CompletedAt.Format(time.RFC3339),
+				Note:        c.Note,
+			}
+			if c.UserID != 0 {
+				completion.UserID = strconv.Itoa(c.UserID)
+			}
+			result = append(result, completion)
+		}
+		if deleted && len(result) == 0 {
+			writeTaskError(w, database.ErrTaskNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"history": result})
+	}
+}
@@ -0,0 +1,376 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"go_final_project/auth"
+	"go_final_project/database"
+	"go_final_project/models"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// errListReadOnly возвращается при попытке добавить задачу в список без прав редактора
+var errListReadOnly = errors.New("Недостаточно прав для добавления задач в список")
+
+// errNotListOwner возвращается при попытке изменить список или его участников не владельцем
+var errNotListOwner = errors.New("Изменять список может только его владелец")
+
+// ListHandler управляет общими списками задач: POST создаёт список, GET возвращает его
+// вместе с участниками, PUT переименовывает, DELETE удаляет
+func ListHandler(lists database.ListStore) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handlePostList(w, r, lists)
+		case http.MethodGet:
+			handleGetList(w, r, lists)
+		case http.MethodPut:
+			handlePutList(w, r, lists)
+		case http.MethodDelete:
+			handleDeleteList(w, r, lists)
+		default:
+			http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// GetLists возвращает списки, которыми владеет пользователь или в которых он участвует;
+// администратору — все списки
+func GetLists(lists database.ListStore) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+			return
+		}
+
+		user := auth.FromContext(r.Context())
+		userLists, err := lists.Lists(user.ID, user.IsAdmin())
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		result := []models.List{}
+		for _, list := range userLists {
+			result = append(result, models.List{
+				ID:      strconv.Itoa(list.ID),
+				Name:    list.Name,
+				OwnerID: strconv.Itoa(list.OwnerID),
+				Role:    list.Role,
+			})
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"lists": result})
+	}
+}
+
+func handlePostList(w http.ResponseWriter, r *http.Request, lists database.ListStore) {
+	var list models.List
+	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+		http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+		return
+	}
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		http.Error(w, `{"error":"Не указано название списка"}`, http.StatusBadRequest)
+		return
+	}
+
+	id, err := lists.CreateList(list.Name, auth.FromContext(r.Context()).ID)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": strconv.Itoa(id)})
+}
+
+func handleGetList(w http.ResponseWriter, r *http.Request, lists database.ListStore) {
+	listID, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор списка должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+	role, err := userListRole(r, lists, listID)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	list, err := lists.GetList(listID)
+	if err != nil {
+		writeListError(w, err)
+		return
+	}
+	members, err := lists.ListMembers(listID)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := models.List{
+		ID:      strconv.Itoa(list.ID),
+		Name:    list.Name,
+		OwnerID: strconv.Itoa(list.OwnerID),
+		Role:    role,
+		Members: []models.ListMember{},
+	}
+	for _, member := range members {
+		result.Members = append(result.Members, models.ListMember{
+			UserID: strconv.Itoa(member.UserID),
+			Login:  member.Login,
+			Role:   member.Role,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(result)
+}
+
+func handlePutList(w http.ResponseWriter, r *http.Request, lists database.ListStore) {
+	var list models.List
+	if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+		http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+		return
+	}
+	listID, err := strconv.Atoi(list.ID)
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор списка должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+	list.Name = strings.TrimSpace(list.Name)
+	if list.Name == "" {
+		http.Error(w, `{"error":"Не указано название списка"}`, http.StatusBadRequest)
+		return
+	}
+	if err := requireListOwner(r, lists, listID); err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	if err := lists.RenameList(listID, list.Name); err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("{}"))
+}
+
+func handleDeleteList(w http.ResponseWriter, r *http.Request, lists database.ListStore) {
+	listID, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор списка должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+	if err := requireListOwner(r, lists, listID); err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	if err := lists.DeleteList(listID); err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("{}"))
+}
+
+// ListMembersHandler управляет участниками списка: POST приглашает пользователя по логину
+// с ролью viewer или editor либо меняет его роль, DELETE исключает участника.
+// Участник может покинуть список сам.
+func ListMembersHandler(lists database.ListStore) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handlePostListMember(w, r, lists)
+		case http.MethodDelete:
+			handleDeleteListMember(w, r, lists)
+		default:
+			http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func handlePostListMember(w http.ResponseWriter, r *http.Request, lists database.ListStore) {
+	var member models.ListMember
+	if err := json.NewDecoder(r.Body).Decode(&member); err != nil {
+		http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+		return
+	}
+	listID, err := strconv.Atoi(member.ListID)
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор списка должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+	if member.Role == "" {
+		member.Role = database.ListRoleViewer
+	}
+	if member.Role != database.ListRoleViewer && member.Role != database.ListRoleEditor {
+		writeJSONError(w, "Роль участника должна быть viewer или editor", http.StatusBadRequest)
+		return
+	}
+	if err := requireListOwner(r, lists, listID); err != nil {
+		writeListError(w, err)
+		return
+	}
+
+	userID, err := lists.SetListMember(listID, member.Login, member.Role)
+	switch {
+	case errors.Is(err, database.ErrUserNotFound):
+		writeJSONError(w, err.Error(), http.StatusNotFound)
+		return
+	case errors.Is(err, database.ErrListOwnerMember):
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		writeListError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"user_id": strconv.Itoa(userID)})
+}
+
+func handleDeleteListMember(w http.ResponseWriter, r *http.Request, lists database.ListStore) {
+	listID, err := strconv.Atoi(r.URL.Query().Get("list_id"))
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор списка должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+	userID, err := strconv.Atoi(r.URL.Query().Get("user_id"))
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор пользователя должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+	if userID != auth.FromContext(r.Context()).ID {
+		if err := requireListOwner(r, lists, listID); err != nil {
+			writeListError(w, err)
+			return
+		}
+	}
+
+	if err := lists.RemoveListMember(listID, userID); err != nil {
+		if errors.Is(err, database.ErrListMemberNotFound) {
+			http.Error(w, `{"error":"Участник не найден"}`, http.StatusNotFound)
+		} else {
+			writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("{}"))
+}
+
+// userListRole возвращает роль пользователя запроса в списке. Администратору доступны
+// все списки с правами владельца, а для остальных чужой список не отличается от несуществующего.
+func userListRole(r *http.Request, store database.ListRoles, listID int) (string, error) {
+	user := auth.FromContext(r.Context())
+	role, err := store.ListRole(listID, user.ID)
+	if err != nil {
+		return "", err
+	}
+	if user.IsAdmin() {
+		return database.ListRoleOwner, nil
+	}
+	if role == "" {
+		return "", database.ErrListNotFound
+	}
+	return role, nil
+}
+
+// requireListOwner проверяет, что пользователь запроса может изменять список
+func requireListOwner(r *http.Request, store database.ListRoles, listID int) error {
+	role, err := userListRole(r, store, listID)
+	if err != nil {
+		return err
+	}
+	if role != database.ListRoleOwner {
+		return errNotListOwner
+	}
+	return nil
+}
+
+// writeListError отвечает на ошибку доступа к списку
+func writeListError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, database.ErrListNotFound):
+		http.Error(w, `{"error":"Список не найден"}`, http.StatusNotFound)
+	case errors.Is(err, errNotListOwner):
+		writeJSONError(w, err.Error(), http.StatusForbidden)
+	default:
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// taskAssignment проверяет общий список и исполнителя задачи. Пустые поля сохраняют
+// значения задачи current (nil для новой задачи), "0" делает задачу личной или снимает назначение.
+// Добавлять задачи в список могут его владелец и редакторы, исполнителем задачи списка
+// может быть только его участник, а личной задачи — только её владелец.
+func taskAssignment(r *http.Request, store database.TaskStore, task models.Task, current *database.Task) (int, int, error) {
+	user := auth.FromContext(r.Context())
+	var listID, assigneeID int
+	ownerID := user.ID
+	if current != nil {
+		listID, assigneeID, ownerID = current.ListID, current.AssigneeID, current.OwnerID
+	}
+
+	var err error
+	if task.ListID != "" {
+		listID, err = strconv.Atoi(task.ListID)
+		if err != nil {
+			return 0, 0, errors.New("Идентификатор списка должен быть числом")
+		}
+	}
+	if task.AssigneeID != "" {
+		assigneeID, err = strconv.Atoi(task.AssigneeID)
+		if err != nil {
+			return 0, 0, errors.New("Идентификатор исполнителя должен быть числом")
+		}
+	}
+	listChanged := current == nil || listID != current.ListID
+
+	if listID != 0 && listChanged {
+		role, err := userListRole(r, store, listID)
+		if err != nil {
+			return 0, 0, err
+		}
+		if role != database.ListRoleOwner && role != database.ListRoleEditor {
+			return 0, 0, errListReadOnly
+		}
+	}
+
+	if assigneeID != 0 && (listChanged || assigneeID != current.AssigneeID) {
+		if listID == 0 {
+			if assigneeID != ownerID {
+				return 0, 0, errors.New("Личную задачу можно назначить только её владельцу")
+			}
+		} else {
+			role, err := store.ListRole(listID, assigneeID)
+			if err != nil {
+				return 0, 0, err
+			}
+			if role == "" {
+				return 0, 0, errors.New("Исполнитель не участвует в списке")
+			}
+		}
+	}
+	return listID, assigneeID, nil
+}
+
+// writeAssignmentError отвечает на ошибку проверки списка и исполнителя задачи
+func writeAssignmentError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, database.ErrListNotFound):
+		http.Error(w, `{"error":"Список не найден"}`, http.StatusNotFound)
+	case errors.Is(err, errListReadOnly):
+		writeJSONError(w, err.Error(), http.StatusForbidden)
+	default:
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+// Количество задач на странице списка по умолчанию и наибольшее
+const (
+	defaultTasksLimit = 50
+	maxTasksLimit     = 500
+)
+
+// taskSorts — поля сортировки списка задач. Задачи на одну дату упорядочиваются
+// по времени, задачи без времени идут первыми.
+var taskSorts = map[string]bool{"date": true, "title": true, "id": true, "relevance": true}
+
+// taskCursor — позиция в списке задач: значение поля сортировки и идентификатор
+// последней задачи страницы. Sort защищает от использования курсора с другой сортировкой.
+type taskCursor struct {
+	Sort string      `json:"s"`
+	Key  interface{} `json:"k"`
+	ID   int         `json:"id"`
+}
+
+func encodeCursor(cursor taskCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeCursor(value string) (taskCursor, error) {
+	var cursor taskCursor
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return cursor, errors.New("Курсор представлен в неверном формате")
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, errors.New("Курсор представлен в неверном формате")
+	}
+	return cursor, nil
+}
+
+// parseTasksLimit возвращает количество задач на странице из параметра limit
+func parseTasksLimit(r *http.Request) (int, error) {
+	value := r.URL.Query().Get("limit")
+	if value == "" {
+		return defaultTasksLimit, nil
+	}
+	limit, err := strconv.Atoi(value)
+	if err != nil || limit < 1 || limit > maxTasksLimit {
+		return 0, errors.New("Параметр limit должен быть числом от 1 до 500")
+	}
+	return limit, nil
+}
+
+// parseTasksSort возвращает поле и направление сортировки из параметров sort и order.
+// По умолчанию результаты полнотекстового поиска упорядочиваются по релевантности,
+// остальные задачи — по дате.
+func parseTasksSort(r *http.Request, fullText bool) (string, string, error) {
+	field := r.URL.Query().Get("sort")
+	switch {
+	case field == "" && fullText:
+		field = "relevance"
+	case field == "":
+		field = "date"
+	case field == "relevance" && !fullText:
+		return "", "", errors.New("Сортировка по релевантности доступна только при полнотекстовом поиске")
+	case !taskSorts[field]:
+		return "", "", errors.New("Сортировка возможна по полям date, title, id и relevance")
+	}
+
+	order := r.URL.Query().Get("order")
+	switch order {
+	case "", "asc":
+		order = "ASC"
+	case "desc":
+		order = "DESC"
+	default:
+		return "", "", errors.New("Направление сортировки должно быть asc или desc")
+	}
+	return field, order, nil
+}
@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"go_final_project/auth"
+	"go_final_project/database"
+	"go_final_project/models"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// TokensHandler управляет персональными токенами API пользователя: GET возвращает токены
+// без их значений, POST создаёт токен с названием и областью действия, DELETE отзывает его.
+// Управлять токенами можно после входа по паролю или с токеном области admin.
+func TokensHandler(db *database.DB) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if scope := auth.FromContext(r.Context()).Scope; scope != "" && scope != auth.ScopeAdmin {
+			writeJSONError(w, "Для управления токенами нужен токен с областью admin", http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			handleGetTokens(w, r, db)
+		case http.MethodPost:
+			handlePostToken(w, r, db)
+		case http.MethodDelete:
+			handleDeleteToken(w, r, db)
+		default:
+			http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+func handleGetTokens(w http.ResponseWriter, r *http.Request, db *database.DB) {
+	tokens, err := database.GetAPITokens(db, auth.FromContext(r.Context()).ID)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	result := []models.APIToken{}
+	for _, token := range tokens {
+		item := models.APIToken{
+			ID:        strconv.Itoa(token.ID),
+			Name:      token.Name,
+			Scope:     token.Scope,
+			CreatedAt: token.CreatedAt.Format(time.RFC3339),
+		}
+		if !token.LastUsedAt.IsZero() {
+			item.LastUsedAt = token.LastUsedAt.Format(time.RFC3339)
+		}
+		result = append(result, item)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{"tokens": result})
+}
+
+func handlePostToken(w http.ResponseWriter, r *http.Request, db *database.DB) {
+	var request models.APIToken
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, `{"error":"Ошибка десериализации JSON"}`, http.StatusBadRequest)
+		return
+	}
+	request.Name = strings.TrimSpace(request.Name)
+	if request.Name == "" {
+		http.Error(w, `{"error":"Не указано название токена"}`, http.StatusBadRequest)
+		return
+	}
+	switch request.Scope {
+	case auth.ScopeRead, auth.ScopeWrite, auth.ScopeAdmin:
+	default:
+		writeJSONError(w, "Область действия токена должна быть read, write или admin", http.StatusBadRequest)
+		return
+	}
+
+	token, hash, err := auth.NewAPIToken()
+	if err != nil {
+		writeJSONError(w, "Ошибка при создании токена", http.StatusInternalServerError)
+		return
+	}
+	id, err := database.CreateAPIToken(db, database.APIToken{
+		UserID:    auth.FromContext(r.Context()).ID,
+		Name:      request.Name,
+		Scope:     request.Scope,
+		CreatedAt: time.Now(),
+	}, hash)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"id": strconv.Itoa(id), "token": token})
+}
+
+func handleDeleteToken(w http.ResponseWriter, r *http.Request, db *database.DB) {
+	id, err := strconv.Atoi(r.URL.Query().Get("id"))
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор токена должен быть числом"}`, http.StatusBadRequest)
+		return
+	}
+
+	err = database.DeleteAPIToken(db, id, auth.FromContext(r.Context()).ID)
+	if errors.Is(err, database.ErrAPITokenNotFound) {
+		http.Error(w, `{"error":"Токен не найден"}`, http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("{}"))
+}
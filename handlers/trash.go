@@ -0,0 +1,169 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"go_final_project/auth"
+	"go_final_project/database"
+	"go_final_project/models"
+	"net/http"
+	"strconv"
+)
+
+// TrashHandler управляет корзиной: GET возвращает удалённые задачи, а с параметром
+// archived=true — архив выполненных одноразовых задач; DELETE ?id= удаляет задачу
+// из корзины или архива окончательно
+func TrashHandler(store database.TaskStore) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			handleGetTrash(w, r, store)
+		case http.MethodDelete:
+			handlePurgeTask(w, r, store)
+		default:
+			http.Error(w, "Метод не разрешен", http.StatusMethodNotAllowed)
+		}
+	}
+}
+
+// RestoreHandler возвращает задачу из корзины или архива в список задач
+func RestoreHandler(store database.TaskStore) func(w http.ResponseWriter, r *http.Request) {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Метод не поддерживается", http.StatusMethodNotAllowed)
+			return
+		}
+
+		taskID, ok := taskIDParam(w, r)
+		if !ok {
+			return
+		}
+		if err := removedTask(r, store, taskID); err != nil {
+			writeRemovedTaskError(w, err)
+			return
+		}
+		if err := store.RestoreTask(taskID); err != nil {
+			writeRemovedTaskError(w, err)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte("{}"))
+	}
+}
+
+
+func handleGetTrash(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
+	user := auth.FromContext(r.Context())
+	filter := database.TaskFilter{
+		UserID:     user.ID,
+		All:        user.IsAdmin(),
+		State:      database.TasksDeleted,
+		Sort:       "deleted",
+		Desc:       true,
+		CountTotal: true,
+	}
+	switch r.URL.Query().Get("archived") {
+	case "", "false":
+	case "true":
+		filter.State, filter.Sort = database.TasksArchived, "archived"
+	default:
+		writeJSONError(w, "Параметр archived должен быть true или false", http.StatusBadRequest)
+		return
+	}
+
+	var err error
+	filter.Limit, err = parseTasksLimit(r)
+	if err != nil {
+		writeJSONError(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	// Задачи упорядочены от последней перемещённой в корзину или архив,
+	// перемещённые одновременно — от последней созданной
+	trashSort := filter.Sort + " DESC"
+	if value := r.URL.Query().Get("cursor"); value != "" {
+		cursor, err := decodeCursor(value)
+		if err == nil && cursor.Sort != trashSort {
+			err = errors.New("Курсор получен для другой сортировки")
+		}
+		if err != nil {
+			writeJSONError(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		filter.After = &database.TaskCursor{Key: cursor.Key, ID: cursor.ID}
+	}
+
+	page, err := store.ListTasks(filter)
+	if err != nil {
+		http.Error(w, `{"error": "Ошибка выполнения запроса"}`, http.StatusInternalServerError)
+		return
+	}
+
+	lang := requestLang(r)
+	tasks := []models.Task{}
+	for _, listed := range page.Tasks {
+		tasks = append(tasks, listedTask(listed, lang))
+	}
+	response := map[string]interface{}{"tasks": tasks, "total": page.Total}
+	if page.More && len(page.Tasks) > 0 {
+		last := page.Tasks[len(page.Tasks)-1]
+		response["next_cursor"] = encodeCursor(taskCursor{Sort: trashSort, Key: last.SortKey, ID: last.ID})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(response)
+}
+
+func handlePurgeTask(w http.ResponseWriter, r *http.Request, store database.TaskStore) {
+	taskID, ok := taskIDParam(w, r)
+	if !ok {
+		return
+	}
+	if err := removedTask(r, store, taskID); err != nil {
+		writeRemovedTaskError(w, err)
+		return
+	}
+	if err := store.PurgeTask(taskID); err != nil {
+		writeRemovedTaskError(w, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Write([]byte("{}"))
+}
+
+// taskIDParam возвращает идентификатор задачи из параметра id или отвечает ошибкой
+func taskIDParam(w http.ResponseWriter, r *http.Request) (int, bool) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		http.Error(w, `{"error":"Не указан идентификатор задачи"}`, http.StatusBadRequest)
+		return 0, false
+	}
+	taskID, err := strconv.Atoi(idStr)
+	if err != nil {
+		http.Error(w, `{"error":"Идентификатор задачи должен быть числом"}`, http.StatusBadRequest)
+		return 0, false
+	}
+	return taskID, true
+}
+
+// removedTask проверяет, что задача находится в корзине или архиве
+// и пользователь запроса может её изменять
+func removedTask(r *http.Request, store database.TaskStore, taskID int) error {
+	task, err := store.GetRemovedTask(taskID)
+	if err != nil {
+		return err
+	}
+	_, err = taskAccess(r, store, task, true)
+	return err
+}
+
+// writeRemovedTaskError отвечает на ошибку получения задачи из корзины
+func writeRemovedTaskError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, database.ErrTaskNotFound), errors.Is(err, errReadOnly):
+		writeTaskError(w, err)
+	default:
+		writeJSONError(w, err.Error(), http.StatusInternalServerError)
+	}
+}
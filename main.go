@@ -1,14 +1,36 @@
 package main
 
 import (
+	"go_final_project/calendar"
 	"go_final_project/database"
 	"go_final_project/handlers"
+	"go_final_project/utils"
 	"log"
 	"net/http"
 	"os"
+	"time"
+	// База часовых поясов встраивается в бинарный файл: в образе контейнера её может не быть
+	_ "time/tzdata"
 )
 
 func main() {
+	// Команда migrate управляет версией схемы базы данных, не запуская сервер
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := runMigrate(os.Args[2:]); err != nil {
+			log.Printf("Ошибка миграции: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+	// Команда role назначает роль пользователю, не запуская сервер
+	if len(os.Args) > 1 && os.Args[1] == "role" {
+		if err := runRole(os.Args[2:]); err != nil {
+			log.Printf("Ошибка назначения роли: %v", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	db, err := database.CreateOrGetDb()
 	if err != nil {
 		log.Printf("Ошибка при создании или открытии базы данных: %v", err)
@@ -16,18 +38,66 @@ func main() {
 	}
 	defer db.Close()
 
+	// Производственный календарь для правил с рабочими днями, по умолчанию — встроенный российский
+	if holidaysFile := os.Getenv("TODO_HOLIDAYS"); holidaysFile != "" {
+		cal, err := calendar.Load(holidaysFile)
+		if err != nil {
+			log.Printf("Ошибка при загрузке календаря: %v", err)
+			return
+		}
+		calendar.SetDefault(cal)
+	}
+
+	// Часовой пояс задач, для которых он не указан, по умолчанию — пояс сервера
+	if timezone := os.Getenv("TODO_TIMEZONE"); timezone != "" {
+		loc, err := time.LoadLocation(timezone)
+		if err != nil {
+			log.Printf("Ошибка при загрузке часового пояса: %v", err)
+			return
+		}
+		utils.SetDefaultLocation(loc)
+	}
+
 	port := os.Getenv("TODO_PORT")
 	if port == "" {
 		port = "7540"
 	}
 
+	// Секрет для подписи токенов и общий пароль администратора; без него API и веб-интерфейс
+	// доступны без аутентификации
+	password := os.Getenv("TODO_PASSWORD")
+	auth := handlers.Auth(db, password)
+	store := database.NewStore(db)
+
+	// Задачи из корзины удаляются окончательно после срока хранения
+	retention, err := trashRetention()
+	if err != nil {
+		log.Printf("Ошибка в настройках корзины: %v", err)
+		return
+	}
+	if retention > 0 {
+		go purgeTrash(store, retention)
+	}
+
 	mux := http.NewServeMux()
 	webDir := "./web"
-	mux.Handle("/", http.FileServer(http.Dir(webDir)))
-	mux.HandleFunc("/api/nextdate", handlers.NextDateHandler)
-	mux.HandleFunc("/api/task", handlers.TaskHandler(db))
-	mux.HandleFunc("/api/tasks", handlers.GetTasks(db))
-	mux.HandleFunc("/api/task/done", handlers.HandlePostTaskDone(db))
+	mux.Handle("/", handlers.AuthPage(db, password, http.FileServer(http.Dir(webDir))))
+	mux.HandleFunc("/api/signin", handlers.SigninHandler(db, password))
+	mux.HandleFunc("/api/signup", handlers.SignupHandler(db, password))
+	mux.HandleFunc("/api/nextdate", auth(handlers.NextDateHandler))
+	mux.HandleFunc("/api/occurrences", auth(handlers.OccurrencesHandler))
+	mux.HandleFunc("/api/parse", auth(handlers.ParseHandler))
+	mux.HandleFunc("/api/task", auth(handlers.TaskHandler(store)))
+	mux.HandleFunc("/api/tasks", auth(handlers.GetTasks(store)))
+	mux.HandleFunc("/api/task/done", auth(handlers.HandlePostTaskDone(store)))
+	mux.HandleFunc("/api/task/history", auth(handlers.HistoryHandler(store)))
+	mux.HandleFunc("/api/trash", auth(handlers.TrashHandler(store)))
+	mux.HandleFunc("/api/trash/restore", auth(handlers.RestoreHandler(store)))
+	mux.HandleFunc("/api/task/exceptions", auth(handlers.ExceptionsHandler(store)))
+	mux.HandleFunc("/api/list", auth(handlers.ListHandler(store)))
+	mux.HandleFunc("/api/lists", auth(handlers.GetLists(store)))
+	mux.HandleFunc("/api/list/members", auth(handlers.ListMembersHandler(store)))
+	mux.HandleFunc("/api/tokens", auth(handlers.TokensHandler(db)))
 
 	err = http.ListenAndServe(":"+port, mux)
 	if err != nil {
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go_final_project/database"
+	"strconv"
+)
+
+const migrateUsage = "использование: migrate status | up | down | to <версия>"
+
+// runMigrate выполняет команду migrate: status показывает применённые миграции,
+// up обновляет схему до последней версии, down откатывает последнюю миграцию,
+// to переводит схему к указанной версии
+func runMigrate(args []string) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	current, err := database.SchemaVersion(db)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "status" && len(args) == 1:
+		migrations, err := database.Migrations(db)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Версия схемы: %d из %d\n", current, database.LatestVersion())
+		for _, m := range migrations {
+			mark := " "
+			if m.AppliedAt != "" {
+				mark = "x"
+			}
+			fmt.Printf("[%s] %d %s %s\n", mark, m.Version, m.Name, m.AppliedAt)
+		}
+		return nil
+	case args[0] == "up" && len(args) == 1:
+		return database.MigrateTo(db, database.LatestVersion())
+	case args[0] == "down" && len(args) == 1:
+		if current == 0 {
+			return errors.New("нет применённых миграций")
+		}
+		return database.MigrateTo(db, current-1)
+	case args[0] == "to" && len(args) == 2:
+		target, err := strconv.Atoi(args[1])
+		if err != nil {
+			return errors.New("версия схемы должна быть числом")
+		}
+		return database.MigrateTo(db, target)
+	}
+	return errors.New(migrateUsage)
+}
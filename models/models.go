@@ -1,9 +1,92 @@
 package models
 
+// Режимы повторения: следующая дата отсчитывается от даты задачи или от даты выполнения
+const (
+	RepeatModeDue        = "due"
+	RepeatModeCompletion = "completion"
+)
+
 type Task struct {
+	ID         string `json:"id"`
+	Date       string `json:"date"`
+	Title      string `json:"title"`
+	Comment    string `json:"comment,omitempty"`
+	Repeat     string `json:"repeat,omitempty"`
+	RepeatText string `json:"repeat_text,omitempty"`
+	// RepeatUntil — дата окончания повторений в формате YYYYMMDD
+	RepeatUntil string `json:"repeat_until,omitempty"`
+	// RepeatCount — сколько раз задачу нужно выполнить, прежде чем она будет перемещена в архив
+	RepeatCount string `json:"repeat_count,omitempty"`
+	// DoneCount — сколько раз задача уже выполнена, только для чтения
+	DoneCount string `json:"done_count,omitempty"`
+	// RepeatMode — режим повторения: RepeatModeDue или RepeatModeCompletion
+	RepeatMode string `json:"repeat_mode,omitempty"`
+	// Time — время выполнения в формате HH:MM
+	Time string `json:"time,omitempty"`
+	// Timezone — часовой пояс IANA, например Europe/Moscow
+	Timezone string `json:"timezone,omitempty"`
+	// DueLocal и DueUTC — момент выполнения в часовом поясе задачи и в UTC (RFC 3339), только для чтения
+	DueLocal string `json:"due_local,omitempty"`
+	DueUTC   string `json:"due_utc,omitempty"`
+	// ListID — общий список задачи, "0" при изменении делает задачу личной
+	ListID string `json:"list_id,omitempty"`
+	// AssigneeID — исполнитель задачи, "0" при изменении снимает назначение
+	AssigneeID string `json:"assignee_id,omitempty"`
+	// Snippet — фрагмент заголовка или комментария с найденными словами, выделенными <mark>
+	Snippet string `json:"snippet,omitempty"`
+	Text    string `json:"text,omitempty"`
+	// DeletedAt и ArchivedAt — время перемещения задачи в корзину и в архив, RFC 3339
+	DeletedAt  string `json:"deleted_at,omitempty"`
+	ArchivedAt string `json:"archived_at,omitempty"`
+}
+
+// Exception — исключение для одного повторения задачи: отмена или перенос
+type Exception struct {
 	ID      string `json:"id"`
+	TaskID  string `json:"task_id"`
 	Date    string `json:"date"`
-	Title   string `json:"title"`
+	Skip    bool   `json:"skip,omitempty"`
+	NewDate string `json:"new_date,omitempty"`
+	Title   string `json:"title,omitempty"`
 	Comment string `json:"comment,omitempty"`
-	Repeat  string `json:"repeat,omitempty"`
+}
+
+// List — общий список задач
+type List struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	OwnerID string `json:"owner_id,omitempty"`
+	// Role — роль текущего пользователя в списке: owner, editor или viewer
+	Role    string       `json:"role,omitempty"`
+	Members []ListMember `json:"members,omitempty"`
+}
+
+// ListMember — участник общего списка
+type ListMember struct {
+	ListID string `json:"list_id,omitempty"`
+	UserID string `json:"user_id,omitempty"`
+	Login  string `json:"login,omitempty"`
+	Role   string `json:"role"`
+}
+
+// APIToken — персональный токен API. Сам токен возвращается только при создании.
+type APIToken struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Scope      string `json:"scope"`
+	Token      string `json:"token,omitempty"`
+	CreatedAt  string `json:"created_at,omitempty"`
+	LastUsedAt string `json:"last_used_at,omitempty"`
+}
+
+// Completion — запись истории выполнения задачи
+type Completion struct {
+	ID     string `json:"id"`
+	TaskID string `json:"task_id"`
+	Title  string `json:"title"`
+	// Date — дата, на которую была назначена выполненная задача
+	Date        string `json:"date"`
+	CompletedAt string `json:"completed_at"`
+	UserID      string `json:"user_id,omitempty"`
+	Note        string `json:"note,omitempty"`
 }
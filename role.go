@@ -0,0 +1,31 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go_final_project/auth"
+	"go_final_project/database"
+)
+
+const roleUsage = "использование: role <логин> admin | user"
+
+// runRole выполняет команду role: назначает пользователю роль администратора или
+// обычного пользователя. Регистрация через API создаёт только обычных пользователей,
+// поэтому администраторов назначает тот, у кого есть доступ к серверу.
+func runRole(args []string) error {
+	if len(args) != 2 || (args[1] != auth.RoleAdmin && args[1] != auth.RoleUser) {
+		return errors.New(roleUsage)
+	}
+
+	db, err := database.Open()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	if err := database.SetUserRole(db, args[0], args[1]); err != nil {
+		return err
+	}
+	fmt.Printf("Пользователю %s назначена роль %s\n", args[0], args[1])
+	return nil
+}
@@ -25,8 +25,20 @@ func getURL(path string) string {
 	return fmt.Sprintf("http://localhost:%d/%s", port, path)
 }
 
+// get выполняет GET-запрос, передавая токен из настроек в куке token
+func get(path string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, getURL(path), nil)
+	if err != nil {
+		return nil, err
+	}
+	if len(Token) > 0 {
+		req.AddCookie(&http.Cookie{Name: "token", Value: Token})
+	}
+	return http.DefaultClient.Do(req)
+}
+
 func getBody(path string) ([]byte, error) {
-	resp, err := http.Get(getURL(path))
+	resp, err := get(path)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,90 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMain получает токен через /api/signin, если сервер запущен с паролем
+// из TODO_PASSWORD, а токен не задан в settings.go
+func TestMain(m *testing.M) {
+	if password := os.Getenv("TODO_PASSWORD"); password != "" && len(Token) == 0 {
+		token, err := signin(password)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Не удалось получить токен:", err)
+			os.Exit(1)
+		}
+		Token = token
+	}
+	os.Exit(m.Run())
+}
+
+func signin(password string) (string, error) {
+	data, err := json.Marshal(map[string]string{"password": password})
+	if err != nil {
+		return "", err
+	}
+	resp, err := http.Post(getURL("api/signin"), "application/json", bytes.NewBuffer(data))
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	var m map[string]string
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return "", err
+	}
+	if m["error"] != "" {
+		return "", fmt.Errorf("%s", m["error"])
+	}
+	return m["token"], nil
+}
+
+func TestSignin(t *testing.T) {
+	password := os.Getenv("TODO_PASSWORD")
+	if password == "" {
+		t.Skip("Сервер запущен без пароля")
+	}
+
+	_, err := signin(password + "x")
+	assert.Error(t, err, "Ожидается ошибка для неверного пароля")
+
+	token, err := signin(password)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, token)
+
+	resp, err := http.Get(getURL("api/tasks"))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	req, err := http.NewRequest(http.MethodGet, getURL("api/tasks"), nil)
+	assert.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	req.Header.Set("Authorization", "Bearer "+token+"x")
+	resp, err = http.DefaultClient.Do(req)
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Главная страница без токена перенаправляет на страницу входа
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error {
+		return http.ErrUseLastResponse
+	}}
+	resp, err = client.Get(getURL(""))
+	assert.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusFound, resp.StatusCode)
+	assert.Equal(t, "/login.html", resp.Header.Get("Location"))
+}
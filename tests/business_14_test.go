@@ -0,0 +1,60 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBusinessDays(t *testing.T) {
+	tbl := []struct {
+		now    string
+		date   string
+		repeat string
+		want   string
+	}{
+		{"20240126", "20240126", "b 3", "20240131"},
+		{"20240126", "20240126", "b 1", "20240129"},
+		{"20231229", "20231229", "b 1", "20240109"},
+		{"20240426", "20240426", "b 1", "20240427"},
+		{"20240126", "20240101", "bm 1", "20240201"},
+		{"20240126", "20240101", "bm -1", "20240131"},
+		{"20231229", "20231229", "bm 1", "20240109"},
+		{"20240126", "20240101", "bm 5", "20240207"},
+		{"20240126", "20240126", "b 0", ""},
+		{"20240126", "20240126", "bm 24", ""},
+		{"20240126", "20240126", "b", ""},
+		{"20261230", "20261230", "b 1", "20270111"},
+		{"20270430", "20270430", "b 1", "20270504"},
+		{"20270101", "20270101", "bm 1", "20270111"},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=%s&date=%s&repeat=%s",
+			v.now, v.date, url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q, %q}`,
+			v.now, v.date, v.repeat, v.want)
+	}
+}
+
+// TestBusinessDaysOutsideCalendar проверяет, что за пределами производственного
+// календаря рабочие дни не вычисляются по одним субботам и воскресеньям
+func TestBusinessDaysOutsideCalendar(t *testing.T) {
+	for _, repeat := range []string{"b 3", "bm 1"} {
+		urlPath := fmt.Sprintf("api/nextdate?now=%s&date=%s&repeat=%s",
+			"20271230", "20271230", url.QueryEscape(repeat))
+		body, err := getBody(urlPath)
+		assert.NoError(t, err)
+		assert.Contains(t, string(body), "вне производственного календаря", repeat)
+	}
+}
@@ -0,0 +1,44 @@
+package tests
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCron(t *testing.T) {
+	tbl := []nextDate{
+		{"20240101", "0 9 * * MON-FRI", "20240129"},
+		{"20240126", "0 9 * * 1-5", "20240129"},
+		{"20240101", "30 18 * * 0", "20240128"},
+		{"20240101", "0 0 1,15 * *", "20240201"},
+		{"20240101", "0 0 */10 * *", "20240131"},
+		{"20240101", "0 0 1 */3 *", "20240401"},
+		{"20240101", "0 0 13 * FRI", "20240202"},
+		{"20240101", "0 0 29 FEB *", "20240229"},
+		{"20240101", "@monthly", "20240201"},
+		{"20240101", "@weekly", "20240128"},
+		{"20240101", "0 0 30 2 *", ""},
+		{"20240101", "0 24 * * *", ""},
+		{"20240101", "0 0 * * 8", ""},
+		{"20240101", "0 0 * * MON-XYZ", ""},
+		{"20240101", "@hourly", ""},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
@@ -1,29 +1,67 @@
 package tests
 
 import (
+	"database/sql"
 	"os"
 	"testing"
 	"time"
 
 	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/stretchr/testify/assert"
 )
 
 type Task struct {
-	ID      int64  `db:"id"`
-	Date    string `db:"date"`
-	Title   string `db:"title"`
-	Comment string `db:"comment"`
-	Repeat  string `db:"repeat"`
+	ID          int64  `db:"id"`
+	Date        string `db:"date"`
+	Title       string `db:"title"`
+	Comment     string `db:"comment"`
+	Repeat      string `db:"repeat"`
+	RepeatUntil string `db:"repeat_until"`
+	RepeatCount int    `db:"repeat_count"`
+	DoneCount   int    `db:"done_count"`
+	Time        string `db:"due_time"`
+	Timezone    string `db:"timezone"`
+	RepeatMode  string `db:"repeat_mode"`
+	OwnerID     int64  `db:"owner_id"`
+	ListID      int64  `db:"list_id"`
+	AssigneeID  int64  `db:"assignee_id"`
+	DeletedAt   string `db:"deleted_at"`
+	ArchivedAt  string `db:"archived_at"`
 }
 
-func count(db *sqlx.DB) (int, error) {
+// testDB — база данных приложения. Запросы тестов пишутся с параметрами ?,
+// для PostgreSQL они заменяются на $1, $2, …
+type testDB struct {
+	*sqlx.DB
+}
+
+func (db *testDB) Get(dest interface{}, query string, args ...interface{}) error {
+	return db.DB.Get(dest, db.Rebind(query), args...)
+}
+
+func (db *testDB) Select(dest interface{}, query string, args ...interface{}) error {
+	return db.DB.Select(dest, db.Rebind(query), args...)
+}
+
+func (db *testDB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.DB.Exec(db.Rebind(query), args...)
+}
+
+func count(db *testDB) (int, error) {
 	var count int
 	return count, db.Get(&count, `SELECT count(id) FROM scheduler`)
 }
 
-func openDB(t *testing.T) *sqlx.DB {
+// openDB открывает базу PostgreSQL из TODO_DB_URL, если сервер запущен с ней,
+// иначе файл SQLite из TODO_DBFILE
+func openDB(t *testing.T) *testDB {
+	if url := os.Getenv("TODO_DB_URL"); url != "" {
+		db, err := sqlx.Connect("postgres", url)
+		assert.NoError(t, err)
+		return &testDB{db}
+	}
 	dbfile := DBFile
 	envFile := os.Getenv("TODO_DBFILE")
 	if len(envFile) > 0 {
@@ -31,7 +69,7 @@ func openDB(t *testing.T) *sqlx.DB {
 	}
 	db, err := sqlx.Connect("sqlite3", dbfile)
 	assert.NoError(t, err)
-	return db
+	return &testDB{db}
 }
 
 func TestDB(t *testing.T) {
@@ -43,12 +81,11 @@ func TestDB(t *testing.T) {
 
 	today := time.Now().Format(`20060102`)
 
-	res, err := db.Exec(`INSERT INTO scheduler (date, title, comment, repeat) 
-	VALUES (?, 'Todo', 'Комментарий', '')`, today)
+	var id int64
+	err = db.Get(&id, `INSERT INTO scheduler (date, title, comment, repeat) 
+	VALUES (?, 'Todo', 'Комментарий', '') RETURNING id`, today)
 	assert.NoError(t, err)
 
-	id, err := res.LastInsertId()
-
 	var task Task
 	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
 	assert.NoError(t, err)
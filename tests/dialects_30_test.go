@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go_final_project/database"
+)
+
+// TestDialects проверяет хранилища SQLite и PostgreSQL напрямую, без сервера. Обе базы
+// открываются в одном процессе: диалект хранится в подключении, а не в пакете.
+// Проверка PostgreSQL выполняется, только если задана TODO_DB_URL.
+func TestDialects(t *testing.T) {
+	sqlite, err := database.OpenSQLite(filepath.Join(t.TempDir(), "dialects.db"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer sqlite.Close()
+	assert.NoError(t, database.Setup(sqlite))
+	assert.False(t, sqlite.Postgres())
+
+	var postgres *database.DB
+	if url := os.Getenv("TODO_DB_URL"); url != "" {
+		postgres, err = database.OpenPostgres(url)
+		if !assert.NoError(t, err) {
+			return
+		}
+		defer postgres.Close()
+		assert.NoError(t, database.Setup(postgres))
+		assert.True(t, postgres.Postgres())
+	}
+
+	t.Run("sqlite", func(t *testing.T) {
+		assert.IsType(t, &database.SQLiteStore{}, database.NewStore(sqlite))
+		testDialect(t, sqlite)
+	})
+	t.Run("postgres", func(t *testing.T) {
+		if postgres == nil {
+			t.Skip("TODO_DB_URL не задана")
+		}
+		assert.IsType(t, &database.PostgresStore{}, database.NewStore(postgres))
+		testDialect(t, postgres)
+	})
+}
+
+func testDialect(t *testing.T, db *database.DB) {
+	// Знаки ? в строках запроса не считаются параметрами
+	var text string
+	err := db.QueryRow(`SELECT 'it''s ?' || ?`, "!").Scan(&text)
+	assert.NoError(t, err)
+	assert.Equal(t, "it's ?!", text)
+
+	store := database.NewStore(db)
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+
+	id, err := store.CreateTask(database.Task{
+		Date:       today,
+		Title:      "Standup? " + suffix,
+		Comment:    "Скидка 50%",
+		Repeat:     "d 1",
+		RepeatMode: "due",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer func() {
+		store.DeleteTask(id)
+		store.PurgeTask(id)
+	}()
+
+	// Отмена повторения и перенос задачи сохраняются в одной транзакции
+	tomorrow := today.AddDate(0, 0, 1)
+	_, err = store.SaveException(database.Exception{TaskID: id, Date: today, Skip: true}, tomorrow, "d 1")
+	assert.NoError(t, err)
+	task, err := store.GetTask(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, tomorrow.Format("20060102"), task.Date.Format("20060102"))
+	}
+	exceptions, err := store.Exceptions(id)
+	assert.NoError(t, err)
+	assert.Len(t, exceptions, 1)
+
+	// Выполнение сохраняется в истории вместе с переносом задачи, а выполнение
+	// отсутствующей задачи не оставляет записи в истории
+	tomorrow = tomorrow.AddDate(0, 0, 1)
+	_, err = store.CompleteTask(database.Completion{TaskID: id, Date: task.Date, CompletedAt: now}, task.Date, tomorrow, "d 1")
+	assert.NoError(t, err)
+	// Повторная отметка того же повторения не переносит задачу и не попадает в историю
+	_, err = store.CompleteTask(database.Completion{TaskID: id, Date: task.Date, CompletedAt: now}, task.Date, tomorrow.AddDate(0, 0, 1), "d 1")
+	assert.ErrorIs(t, err, database.ErrTaskChanged)
+	_, err = store.CompleteTask(database.Completion{TaskID: id, Date: task.Date, CompletedAt: now}, task.Date, time.Time{}, "d 1")
+	assert.ErrorIs(t, err, database.ErrTaskChanged)
+	task, err = store.GetTask(id)
+	if assert.NoError(t, err) {
+		assert.Equal(t, tomorrow.Format("20060102"), task.Date.Format("20060102"))
+		assert.Equal(t, 1, task.DoneCount)
+	}
+	_, err = store.CompleteTask(database.Completion{TaskID: -1, Date: today, CompletedAt: now}, today, tomorrow, "")
+	assert.ErrorIs(t, err, database.ErrTaskNotFound)
+	history, err := store.History(-1)
+	assert.NoError(t, err)
+	assert.Empty(t, history)
+	history, err = store.History(id)
+	assert.NoError(t, err)
+	assert.Len(t, history, 1)
+
+	// Поиск по подстроке не учитывает регистр латиницы, а % ищется как обычный символ
+	page, err := store.ListTasks(database.TaskFilter{All: true, Search: "STANDUP? " + suffix, Sort: "id", Limit: 10})
+	if assert.NoError(t, err) && !store.FullTextSearch() {
+		assert.Len(t, page.Tasks, 1)
+	}
+	page, err = store.ListTasks(database.TaskFilter{All: true, Search: "50%", Sort: "id", Limit: 1000})
+	assert.NoError(t, err)
+	found := false
+	for _, task := range page.Tasks {
+		found = found || task.ID == id
+	}
+	assert.True(t, found)
+
+	// Участник добавляется в список по логину
+	owner, err := database.CreateUser(db, "owner"+suffix, "hash")
+	if !assert.NoError(t, err) {
+		return
+	}
+	member, err := database.CreateUser(db, "member"+suffix, "hash")
+	if !assert.NoError(t, err) {
+		return
+	}
+	listID, err := store.CreateList("Список "+suffix, owner.ID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer store.DeleteList(listID)
+	userID, err := store.SetListMember(listID, member.Login, database.ListRoleEditor)
+	assert.NoError(t, err)
+	assert.Equal(t, member.ID, userID)
+	_, err = store.SetListMember(listID, owner.Login, database.ListRoleEditor)
+	assert.ErrorIs(t, err, database.ErrListOwnerMember)
+	role, err := store.ListRole(listID, member.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, database.ListRoleEditor, role)
+}
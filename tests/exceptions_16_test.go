@@ -0,0 +1,149 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExceptionSkip(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Еженедельная встреча",
+		repeat: "d 7",
+	})
+
+	ret, err := postJSON("api/task/exceptions", map[string]any{
+		"task_id": id,
+		"date":    now.AddDate(0, 0, 7).Format(`20060102`),
+		"skip":    true,
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["id"])
+
+	// Отменённое повторение пропускается при отметке о выполнении
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 14).Format(`20060102`), task.Date)
+	assert.Equal(t, 1, task.DoneCount)
+
+	// Отмена текущего повторения сразу переносит задачу на следующее
+	ret, err = postJSON("api/task/exceptions", map[string]any{
+		"task_id": id,
+		"date":    task.Date,
+		"skip":    true,
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["id"])
+
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 21).Format(`20060102`), task.Date)
+	assert.Equal(t, 1, task.DoneCount)
+
+	body, err := requestJSON("api/task/exceptions?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var list struct {
+		Exceptions []map[string]any `json:"exceptions"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &list))
+	assert.Len(t, list.Exceptions, 2)
+
+	for _, e := range list.Exceptions {
+		ret, err = postJSON("api/task/exceptions?id="+e["id"].(string), nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+	body, err = requestJSON("api/task/exceptions?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(body, &list))
+	assert.Empty(t, list.Exceptions)
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
+
+func TestExceptionOverride(t *testing.T) {
+	now := time.Now()
+	id := addTask(t, task{
+		date:    now.Format(`20060102`),
+		title:   "Планёрка",
+		comment: "В переговорной",
+		repeat:  "d 7",
+	})
+
+	ret, err := postJSON("api/task/exceptions", map[string]any{
+		"task_id":  id,
+		"date":     now.Format(`20060102`),
+		"new_date": now.AddDate(0, 0, 1).Format(`20060102`),
+		"title":    "Планёрка (перенесена)",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["id"])
+
+	body, err := requestJSON("api/tasks", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string][]map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+	found := false
+	for _, v := range m["tasks"] {
+		if v["id"] != id {
+			continue
+		}
+		found = true
+		assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), v["date"])
+		assert.Equal(t, "Планёрка (перенесена)", v["title"])
+		assert.Equal(t, "В переговорной", v["comment"])
+	}
+	assert.True(t, found, "Задача %s не найдена в списке", id)
+
+	// Задача по идентификатору показывается так же, как в списке
+	body, err = requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var got map[string]any
+	assert.NoError(t, json.Unmarshal(body, &got))
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), got["date"])
+	assert.Equal(t, "Планёрка (перенесена)", got["title"])
+	assert.Equal(t, "В переговорной", got["comment"])
+
+	tbl := []map[string]any{
+		{"task_id": id, "date": now.AddDate(0, 0, 3).Format(`20060102`), "skip": true},
+		{"task_id": id, "date": now.AddDate(0, 0, 7).Format(`20060102`), "skip": true, "title": "Отмена"},
+		{"task_id": id, "date": now.AddDate(0, 0, 7).Format(`20060102`)},
+		{"task_id": id, "date": "07.01.2030", "skip": true},
+		{"task_id": "99999999", "date": now.Format(`20060102`), "skip": true},
+	}
+	for _, v := range tbl {
+		ret, err := postJSON("api/task/exceptions", v, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := ret["error"]
+		assert.True(t, ok && len(e.(string)) > 0, "Ожидается ошибка для исключения %v", v)
+	}
+
+	// В историю выполнение попадает с перенесённой датой и заголовком повторения
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	history := taskHistory(t, id)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), history[0]["date"])
+		assert.Equal(t, "Планёрка (перенесена)", history[0]["title"])
+	}
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
@@ -0,0 +1,73 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func taskHistory(t *testing.T, id string) []map[string]string {
+	body, err := requestJSON("api/task/history?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m struct {
+		History []map[string]string `json:"history"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &m))
+	return m.History
+}
+
+func TestCompletionHistory(t *testing.T) {
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Полить цветы",
+		repeat: "d 2",
+	})
+	defer postJSON("api/task?id="+id, nil, http.MethodDelete)
+
+	assert.Empty(t, taskHistory(t, id))
+
+	ret, err := postJSON("api/task/done?id="+id, map[string]any{"note": "Фикус и кактус"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	// История начинается с последнего выполнения и хранит дату, на которую была назначена задача
+	history := taskHistory(t, id)
+	if assert.Len(t, history, 2) {
+		assert.Equal(t, now.AddDate(0, 0, 2).Format(`20060102`), history[0]["date"])
+		assert.Empty(t, history[0]["note"])
+		assert.Equal(t, now.Format(`20060102`), history[1]["date"])
+		assert.Equal(t, "Фикус и кактус", history[1]["note"])
+		assert.Equal(t, "Полить цветы", history[1]["title"])
+		_, err := time.Parse(time.RFC3339, history[1]["completed_at"])
+		assert.NoError(t, err)
+	}
+
+	// История одноразовой задачи сохраняется после её удаления при выполнении
+	id = addTask(t, task{
+		date:  now.Format(`20060102`),
+		title: "Оплатить интернет",
+	})
+	ret, err = postJSON("api/task/done?id="+id, map[string]any{"note": "Через банк"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+
+	history = taskHistory(t, id)
+	if assert.Len(t, history, 1) {
+		assert.Equal(t, "Оплатить интернет", history[0]["title"])
+		assert.Equal(t, "Через банк", history[0]["note"])
+	}
+
+	body, err := requestJSON("api/task/history?id=999999999", nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(body, &m))
+	assert.NotEmpty(t, m["error"])
+}
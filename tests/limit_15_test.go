@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatCountDone(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	ret, err := postJSON("api/task", map[string]any{
+		"date":         now.Format(`20060102`),
+		"title":        "Принять лекарство",
+		"repeat":       "d 1",
+		"repeat_count": "2",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id, _ := ret["id"].(string)
+	assert.NotEmpty(t, id)
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 1).Format(`20060102`), task.Date)
+	assert.Equal(t, 2, task.RepeatCount)
+	assert.Equal(t, 1, task.DoneCount)
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+}
+
+func TestRepeatUntilDone(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	ret, err := postJSON("api/task", map[string]any{
+		"date":         now.Format(`20060102`),
+		"title":        "Полить цветы",
+		"repeat":       "d 3",
+		"repeat_until": now.AddDate(0, 0, 4).Format(`20060102`),
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id, _ := ret["id"].(string)
+	assert.NotEmpty(t, id)
+
+	task, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.Contains(t, string(task), now.AddDate(0, 0, 4).Format(`20060102`))
+
+	// Следующая дата через 3 дня ещё не позже даты окончания
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	// Ещё через 3 дня повторения заканчиваются
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+}
+
+func TestRepeatLimitErrors(t *testing.T) {
+	now := time.Now()
+	tbl := []map[string]any{
+		{"date": now.Format(`20060102`), "title": "Без правила", "repeat_count": "3"},
+		{"date": now.Format(`20060102`), "title": "Неверная дата", "repeat": "d 1", "repeat_until": "31.12.2030"},
+		{"date": now.Format(`20060102`), "title": "Отрицательное количество", "repeat": "d 1", "repeat_count": "-1"},
+		{"date": now.AddDate(0, 0, 10).Format(`20060102`), "title": "Окончание раньше даты",
+			"repeat": "d 1", "repeat_until": now.AddDate(0, 0, 5).Format(`20060102`)},
+	}
+	for _, v := range tbl {
+		ret, err := postJSON("api/task", v, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := ret["error"]
+		assert.True(t, ok && len(e.(string)) > 0, "Ожидается ошибка для задачи %v", v)
+	}
+}
@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLists(t *testing.T) {
+	if os.Getenv("TODO_PASSWORD") == "" {
+		t.Skip("Сервер запущен без пароля")
+	}
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	alice := signup(t, "alice"+suffix, "password")
+	bob := signup(t, "bob"+suffix, "password")
+	carol := signup(t, "carol"+suffix, "password")
+	dave := signup(t, "dave"+suffix, "password")
+
+	m, code, err := requestAs(alice, http.MethodPost, "api/list", map[string]any{"name": "Команда " + suffix})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	listID, _ := m["id"].(string)
+	assert.NotEmpty(t, listID)
+
+	invite := func(token, login, role string) (string, int) {
+		m, code, err := requestAs(token, http.MethodPost, "api/list/members", map[string]any{
+			"list_id": listID, "login": login + suffix, "role": role,
+		})
+		assert.NoError(t, err)
+		userID, _ := m["user_id"].(string)
+		return userID, code
+	}
+	bobID, code := invite(alice, "bob", "editor")
+	assert.Equal(t, http.StatusOK, code)
+	_, code = invite(alice, "carol", "viewer")
+	assert.Equal(t, http.StatusOK, code)
+	_, code = invite(bob, "dave", "viewer")
+	assert.Equal(t, http.StatusForbidden, code)
+	_, code = invite(alice, "dave", "admin")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	m, code, err = requestAs(bob, http.MethodGet, "api/lists", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	if assert.Len(t, m["lists"], 1) {
+		list := m["lists"].([]any)[0].(map[string]any)
+		assert.Equal(t, listID, list["id"])
+		assert.Equal(t, "editor", list["role"])
+	}
+
+	m, code, err = requestAs(carol, http.MethodGet, "api/list?id="+listID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "viewer", m["role"])
+	assert.Len(t, m["members"], 2)
+	_, code, err = requestAs(dave, http.MethodGet, "api/list?id="+listID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Исполнителем задачи списка может быть только его участник
+	task := map[string]any{
+		"date":        time.Now().Format(`20060102`),
+		"title":       "Задача списка " + suffix,
+		"list_id":     listID,
+		"assignee_id": "0",
+	}
+	_, code, err = requestAs(carol, http.MethodPost, "api/task", task)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, code)
+	task["assignee_id"] = bobID
+	m, code, err = requestAs(alice, http.MethodPost, "api/task", task)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	id, _ := m["id"].(string)
+
+	taskIDs := func(token, query string) []string {
+		m, _, err := requestAs(token, http.MethodGet, "api/tasks?search="+suffix+query, nil)
+		assert.NoError(t, err)
+		var ids []string
+		tasks, _ := m["tasks"].([]any)
+		for _, v := range tasks {
+			ids = append(ids, v.(map[string]any)["id"].(string))
+		}
+		return ids
+	}
+	assert.Equal(t, []string{id}, taskIDs(bob, "&list="+listID+"&assignee=me"))
+	assert.Equal(t, []string{id}, taskIDs(carol, ""))
+	assert.Empty(t, taskIDs(carol, "&assignee=me"))
+	assert.Empty(t, taskIDs(dave, ""))
+
+	// Наблюдатель не может изменять задачи списка, редактор — может
+	task["id"] = id
+	delete(task, "list_id")
+	delete(task, "assignee_id")
+	_, code, err = requestAs(carol, http.MethodPut, "api/task", task)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, code)
+	_, code, err = requestAs(carol, http.MethodPost, "api/task/done?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, code)
+	task["title"] = "Изменённая задача списка " + suffix
+	_, code, err = requestAs(bob, http.MethodPut, "api/task", task)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+
+	m, code, err = requestAs(carol, http.MethodGet, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, task["title"], m["title"])
+	assert.Equal(t, listID, m["list_id"])
+	assert.Equal(t, bobID, m["assignee_id"])
+
+	// Исключённый участник теряет доступ к задачам списка и назначение
+	_, code, err = requestAs(alice, http.MethodDelete, "api/list/members?list_id="+listID+"&user_id="+bobID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, taskIDs(bob, ""))
+	m, _, err = requestAs(alice, http.MethodGet, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Nil(t, m["assignee_id"])
+
+	// После удаления списка задача остаётся личной задачей владельца
+	_, code, err = requestAs(carol, http.MethodDelete, "api/list?id="+listID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, code)
+	_, code, err = requestAs(alice, http.MethodDelete, "api/list?id="+listID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Empty(t, taskIDs(carol, ""))
+	assert.Equal(t, []string{id}, taskIDs(alice, ""))
+
+	_, code, err = requestAs(alice, http.MethodDelete, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+}
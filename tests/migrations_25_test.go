@@ -0,0 +1,32 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	// Сервер при запуске применяет все миграции по порядку
+	var versions []int
+	err := db.Select(&versions, `SELECT version FROM schema_version ORDER BY version`)
+	assert.NoError(t, err)
+	if assert.NotEmpty(t, versions) {
+		for i, version := range versions {
+			assert.Equal(t, i+1, version)
+		}
+	}
+
+	query := `SELECT count(*) FROM pragma_table_info('scheduler') WHERE name = 'repeat_mode'`
+	if db.DriverName() == "postgres" {
+		query = `SELECT count(*) FROM information_schema.columns
+			WHERE table_name = 'scheduler' AND column_name = 'repeat_mode'`
+	}
+	var columns int
+	err = db.Get(&columns, query)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, columns)
+}
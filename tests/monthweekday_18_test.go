@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMonthWeekday(t *testing.T) {
+	tbl := []struct {
+		now    string
+		date   string
+		repeat string
+		want   string
+	}{
+		{"20240126", "20240126", "mw 2:2", "20240213"},
+		{"20240120", "20240101", "mw -1:5", "20240126"},
+		{"20240126", "20240126", "mw -1:5", "20240223"},
+		{"20240126", "20240126", "mw -1:5 3,6,9,12", "20240329"},
+		{"20240126", "20240126", "mw 5:1", "20240129"},
+		{"20240130", "20240130", "mw 5:1", "20240429"},
+		{"20240126", "20240126", "mw 1:7,3:3", "20240204"},
+		{"20240126", "20240126", "mw 0:1", ""},
+		{"20240126", "20240126", "mw 6:1", ""},
+		{"20240126", "20240126", "mw 2:8", ""},
+		{"20240126", "20240126", "mw 2", ""},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=%s&date=%s&repeat=%s",
+			v.now, v.date, url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q, %q}`,
+			v.now, v.date, v.repeat, v.want)
+	}
+}
+
+func TestParseMonthWeekday(t *testing.T) {
+	tbl := []struct {
+		text   string
+		repeat string
+	}{
+		{"второй вторник каждого месяца", "mw 2:2"},
+		{"последняя пятница квартала", "mw -1:5 3,6,9,12"},
+		{"every first monday of the month", "mw 1:1"},
+		{"last friday of the quarter", "mw -1:5 3,6,9,12"},
+	}
+	for _, v := range tbl {
+		ret, err := postJSON("api/parse", map[string]any{"text": v.text, "now": "20240126"}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Equal(t, v.repeat, ret["repeat"], v.text)
+	}
+}
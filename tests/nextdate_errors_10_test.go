@@ -0,0 +1,50 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextDateErrors(t *testing.T) {
+	tbl := []struct {
+		repeat string
+		status int
+		error  bool
+	}{
+		{"k 34", http.StatusBadRequest, true},
+		{"d", http.StatusBadRequest, true},
+		{"d 401", http.StatusBadRequest, true},
+		{"m 40", http.StatusBadRequest, true},
+		{"w 1,,2", http.StatusBadRequest, true},
+		{"m 31 2", http.StatusBadRequest, true},
+		{"RRULE:FREQ=DAILY;COUNT=3", http.StatusOK, false},
+		{"d 5", http.StatusOK, false},
+	}
+	for _, v := range tbl {
+		resp, err := get(fmt.Sprintf("api/nextdate?now=20240126&date=20240101&repeat=%s",
+			url.QueryEscape(v.repeat)))
+		assert.NoError(t, err)
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		assert.NoError(t, err)
+		assert.Equal(t, v.status, resp.StatusCode, v.repeat)
+
+		var m map[string]string
+		isJSON := json.Unmarshal(body, &m) == nil
+		assert.Equal(t, v.error, isJSON && len(m["error"]) > 0, `%q: %s`, v.repeat, body)
+	}
+
+	m, err := postJSON("api/task", map[string]any{
+		"date":   "20240101",
+		"title":  "Невыполнимая задача",
+		"repeat": "m 30,31 2",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m["error"])
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func getOccurrences(t *testing.T, params url.Values) map[string]any {
+	body, err := requestJSON("api/occurrences?"+params.Encode(), nil, http.MethodGet)
+	assert.NoError(t, err)
+
+	var m map[string]any
+	err = json.Unmarshal(body, &m)
+	assert.NoError(t, err)
+	return m
+}
+
+func TestOccurrences(t *testing.T) {
+	m := getOccurrences(t, url.Values{
+		"now":    {"20240126"},
+		"date":   {"20240101"},
+		"repeat": {"m -1,15 3,6"},
+		"count":  {"5"},
+	})
+	assert.Equal(t, []any{"20240315", "20240331", "20240615", "20240630", "20250315"}, m["dates"])
+	assert.NotEmpty(t, m["description"])
+
+	m = getOccurrences(t, url.Values{
+		"now":    {"20240126"},
+		"date":   {"20240126"},
+		"repeat": {"0 9 * * MON-FRI"},
+		"until":  {"20240205"},
+	})
+	assert.Equal(t, []any{"20240129", "20240130", "20240131", "20240201", "20240202", "20240205"}, m["dates"])
+
+	m = getOccurrences(t, url.Values{
+		"now":    {"20240101"},
+		"date":   {"20240101"},
+		"repeat": {"RRULE:FREQ=MONTHLY;BYDAY=-1FR;COUNT=3"},
+		"count":  {"10"},
+	})
+	assert.Equal(t, []any{"20240126", "20240223", "20240329"}, m["dates"])
+
+	m = getOccurrences(t, url.Values{"repeat": {"ooops"}})
+	assert.NotEmpty(t, m["error"])
+	m = getOccurrences(t, url.Values{"repeat": {"d 1"}, "count": {"0"}})
+	assert.NotEmpty(t, m["error"])
+}
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTasksPagination(t *testing.T) {
+	// Задачи теста назначаются на даты далёкого будущего и выбираются диапазоном from–to,
+	// чтобы задачи других тестов не попадали на страницы
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	now := time.Date(2100, time.January, 1, 0, 0, 0, 0, time.UTC).AddDate(0, 0, int(time.Now().UnixNano()%30000))
+	period := "&from=" + now.Format(`20060102`) + "&to=" + now.AddDate(0, 0, 12).Format(`20060102`)
+	var ids []string
+	for i := 0; i < 7; i++ {
+		repeat := ""
+		if i%2 == 1 {
+			repeat = "d 5"
+		}
+		m, code, err := requestAs(Token, http.MethodPost, "api/task", map[string]any{
+			"date":   now.AddDate(0, 0, i*2).Format(`20060102`),
+			"title":  fmt.Sprintf("%c страница %s", 'G'-i, suffix),
+			"repeat": repeat,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code)
+		ids = append(ids, m["id"].(string))
+	}
+	defer func() {
+		for _, id := range ids {
+			requestAs(Token, http.MethodDelete, "api/task?id="+id, nil)
+		}
+	}()
+
+	// Диапазон дат в params заменяет диапазон задач теста: сервер берёт первое значение параметра
+	page := func(params string) (map[string]any, int) {
+		m, code, err := requestAs(Token, http.MethodGet, "api/tasks?"+strings.TrimPrefix(params+period, "&"), nil)
+		assert.NoError(t, err)
+		return m, code
+	}
+	pageIDs := func(m map[string]any) []string {
+		var result []string
+		tasks, _ := m["tasks"].([]any)
+		for _, v := range tasks {
+			result = append(result, v.(map[string]any)["id"].(string))
+		}
+		return result
+	}
+
+	// Обход всех страниц по курсору возвращает задачи по порядку без пропусков и повторов
+	var all []string
+	params := "&sort=date&limit=3"
+	for pages := 0; pages < 5; pages++ {
+		m, code := page(params)
+		if !assert.Equal(t, http.StatusOK, code, m["error"]) {
+			break
+		}
+		assert.EqualValues(t, 7, m["total"])
+		all = append(all, pageIDs(m)...)
+		cursor, ok := m["next_cursor"].(string)
+		if !ok {
+			break
+		}
+		params = "&sort=date&limit=3&cursor=" + url.QueryEscape(cursor)
+	}
+	assert.Equal(t, ids, all)
+
+	m, code := page("&sort=title&order=desc&limit=2")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{ids[0], ids[1]}, pageIDs(m))
+	m, _ = page("&sort=title&limit=2")
+	assert.Equal(t, []string{ids[6], ids[5]}, pageIDs(m))
+
+	// Фильтры по диапазону дат и наличию повторения
+	m, _ = page("&sort=date&from=" + now.AddDate(0, 0, 2).Format(`20060102`) + "&to=" + now.AddDate(0, 0, 6).Format(`20060102`))
+	assert.Equal(t, ids[1:4], pageIDs(m))
+	m, _ = page("&sort=id&repeating=true")
+	assert.Equal(t, []string{ids[1], ids[3], ids[5]}, pageIDs(m))
+	m, _ = page("&sort=id&repeating=false&limit=10")
+	assert.Equal(t, []string{ids[0], ids[2], ids[4], ids[6]}, pageIDs(m))
+	assert.EqualValues(t, 4, m["total"])
+	assert.Nil(t, m["next_cursor"])
+
+	// Без параметров страниц ответ содержит только список задач
+	m, _ = page("")
+	assert.Len(t, m, 1)
+	assert.Len(t, pageIDs(m), 7)
+
+	m, _ = page("&sort=title&limit=2")
+	cursor := url.QueryEscape(m["next_cursor"].(string))
+	for _, params := range []string{
+		"&limit=0", "&limit=abc", "&sort=color", "&order=up", "&from=2024", "&repeating=yes",
+		"&cursor=xyz", "&sort=date&cursor=" + cursor,
+	} {
+		_, code := page(params)
+		assert.Equal(t, http.StatusBadRequest, code, params)
+	}
+}
@@ -0,0 +1,97 @@
+package tests
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePhrase(t *testing.T) {
+	// 26.01.2024 — пятница
+	tbl := []struct {
+		text   string
+		date   string
+		repeat string
+	}{
+		{"каждый понедельник", "20240129", "w 1"},
+		{"по понедельникам и четвергам", "20240129", "w 1,4"},
+		{"every 2 weeks", "20240126", "d 14"},
+		{"каждые 3 дня", "20240126", "d 3"},
+		{"ежегодно", "20240126", "y"},
+		{"каждый последний день месяца", "20240131", "m -1"},
+		{"завтра", "20240127", ""},
+		{"послезавтра", "20240128", ""},
+		{"next friday", "20240202", ""},
+		{"в среду", "20240131", ""},
+		{"через 2 недели", "20240209", ""},
+		{"каждую среду начиная с 01.03.2024", "20240301", "w 3"},
+	}
+	for _, v := range tbl {
+		m, err := postJSON("api/parse", map[string]any{"text": v.text, "now": "20240126"}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, m["error"], v.text)
+		assert.Equal(t, v.date, m["date"], v.text)
+		assert.Equal(t, v.repeat, m["repeat"], v.text)
+	}
+
+	// Ежемесячная задача с 29-го по 31-е число повторяется в последний день месяца,
+	// а начинается сегодня
+	for _, now := range []string{"20240129", "20240130", "20240131"} {
+		m, err := postJSON("api/parse", map[string]any{"text": "ежемесячно", "now": now}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, m["error"], now)
+		assert.Equal(t, now, m["date"], now)
+		assert.Equal(t, "m -1", m["repeat"], now)
+	}
+	m, err := postJSON("api/parse", map[string]any{"text": "every month", "now": "20240128"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "20240128", m["date"])
+	assert.Equal(t, "m 28", m["repeat"])
+	m, err = postJSON("api/parse", map[string]any{"text": "каждые 2 месяца", "now": "20240731"}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Equal(t, "20240731", m["date"])
+	assert.Equal(t, "RRULE:FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=-1", m["repeat"])
+
+	// Повторение через два месяца не пропускает месяцы без 31-го числа
+	next, err := getBody("api/nextdate?now=20240731&date=20240731&repeat=" +
+		url.QueryEscape("RRULE:FREQ=MONTHLY;INTERVAL=2;BYMONTHDAY=-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "20240930", string(next))
+
+	for _, text := range []string{"", "Созвон завтра", "завтра сегодня", "каждые 60 недель"} {
+		m, err := postJSON("api/parse", map[string]any{"text": text}, http.MethodPost)
+		assert.NoError(t, err)
+		assert.NotEmpty(t, m["error"], text)
+	}
+}
+
+func TestAddTaskText(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	m, err := postJSON("api/task", map[string]any{
+		"title": "Вынести мусор",
+		"text":  "завтра",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, m["error"])
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, m["id"])
+	assert.NoError(t, err)
+	assert.Equal(t, time.Now().AddDate(0, 0, 1).Format(`20060102`), task.Date)
+	assert.Equal(t, "", task.Repeat)
+
+	m, err = postJSON("api/task", map[string]any{
+		"title": "Полить цветы",
+		"text":  "каждые 3 дня",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, m["error"])
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, m["id"])
+	assert.NoError(t, err)
+	assert.Equal(t, "d 3", task.Repeat)
+}
@@ -0,0 +1,130 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatModeDone(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	tbl := []struct {
+		mode string
+		want string
+	}{
+		{"", now.AddDate(0, 0, 9).Format(`20060102`)},
+		{"due", now.AddDate(0, 0, 9).Format(`20060102`)},
+		{"completion", now.AddDate(0, 0, 7).Format(`20060102`)},
+	}
+	for _, v := range tbl {
+		ret, err := postJSON("api/task", map[string]any{
+			"date":        now.AddDate(0, 0, 2).Format(`20060102`),
+			"title":       "Полить цветы",
+			"repeat":      "d 7",
+			"repeat_mode": v.mode,
+		}, http.MethodPost)
+		assert.NoError(t, err)
+		id, _ := ret["id"].(string)
+		assert.NotEmpty(t, id)
+
+		ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+
+		var task Task
+		err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+		assert.NoError(t, err)
+		assert.Equal(t, v.want, task.Date, "режим %q", v.mode)
+
+		ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+	}
+}
+
+func TestEditRepeatMode(t *testing.T) {
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Оплатить интернет",
+		repeat: "m 10",
+	})
+
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+	assert.Equal(t, "due", m["repeat_mode"])
+
+	ret, err := postJSON("api/task", map[string]any{
+		"id":          id,
+		"date":        m["date"],
+		"title":       m["title"],
+		"repeat":      m["repeat"],
+		"repeat_mode": "completion",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	body, err = requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(body, &m))
+	assert.Equal(t, "completion", m["repeat_mode"])
+
+	ret, err = postJSON("api/task", map[string]any{
+		"id":          id,
+		"date":        m["date"],
+		"title":       m["title"],
+		"repeat":      m["repeat"],
+		"repeat_mode": "whenever",
+	}, http.MethodPut)
+	assert.NoError(t, err)
+	e, ok := ret["error"]
+	assert.True(t, ok && len(e.(string)) > 0, "Ожидается ошибка для неизвестного режима")
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
+
+// TestRepeatModeCount проверяет, что задача с COUNT=3 в режиме "completion" уходит
+// в архив после трёх выполнений, даже если день выполнения не совпадает с днём правила
+func TestRepeatModeCount(t *testing.T) {
+	tomorrow := time.Now().AddDate(0, 0, 1)
+	weekday := []string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}[tomorrow.Weekday()]
+	ret, err := postJSON("api/task", map[string]any{
+		"date":        tomorrow.Format(`20060102`),
+		"title":       "Планёрка",
+		"repeat":      "RRULE:FREQ=WEEKLY;BYDAY=" + weekday + ";COUNT=3",
+		"repeat_mode": "completion",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id, _ := ret["id"].(string)
+	if !assert.NotEmpty(t, id) {
+		return
+	}
+
+	for _, want := range []string{"COUNT=2", "COUNT=1"} {
+		ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+		assert.NoError(t, err)
+		assert.Empty(t, ret)
+
+		body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+		assert.NoError(t, err)
+		var m map[string]string
+		assert.NoError(t, json.Unmarshal(body, &m))
+		assert.Contains(t, m["repeat"], want)
+		assert.Equal(t, tomorrow.Format(`20060102`), m["date"])
+	}
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+}
@@ -0,0 +1,46 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepeatText(t *testing.T) {
+	tbl := []struct {
+		repeat string
+		ru     string
+		en     string
+	}{
+		{"d 5", "каждые 5 дней", "every 5 days"},
+		{"d 1", "каждый день", "every day"},
+		{"y", "ежегодно", "every year"},
+		{"m -1,15 3,6", "в последний и 15-й день марта и июня", "on the last and 15th day of March and June"},
+		{"w 1,4", "по понедельникам и четвергам", "every Monday and Thursday"},
+		{"RRULE:FREQ=MONTHLY;BYDAY=-1FR", "ежемесячно, последняя пятница", "monthly, on the last Friday"},
+		{"0 9 * * MON-FRI", "по понедельникам, вторникам, средам, четвергам и пятницам",
+			"every Monday, Tuesday, Wednesday, Thursday and Friday"},
+	}
+
+	now := time.Now()
+	for _, v := range tbl {
+		id := addTask(t, task{
+			date:   now.Format(`20060102`),
+			title:  "Описание правила",
+			repeat: v.repeat,
+		})
+		for lang, want := range map[string]string{"ru": v.ru, "en": v.en} {
+			body, err := requestJSON("api/task?id="+id+"&lang="+lang, nil, http.MethodGet)
+			assert.NoError(t, err)
+			var m map[string]string
+			err = json.Unmarshal(body, &m)
+			assert.NoError(t, err)
+			assert.Equal(t, want, m["repeat_text"], v.repeat)
+		}
+		_, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+		assert.NoError(t, err)
+	}
+}
@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRRule(t *testing.T) {
+	tbl := []nextDate{
+		{"20240101", "RRULE:FREQ=MONTHLY;BYDAY=-1FR", "20240223"},
+		{"20240101", "RRULE:FREQ=MONTHLY;BYDAY=2TU", "20240213"},
+		{"20240101", "RRULE:FREQ=WEEKLY;BYDAY=MO,TH", "20240129"},
+		{"20240101", "RRULE:FREQ=WEEKLY;INTERVAL=2", "20240129"},
+		{"20240101", "RRULE:FREQ=DAILY;COUNT=30", "20240127"},
+		{"20240101", "RRULE:FREQ=DAILY;COUNT=10", ""},
+		{"20240101", "RRULE:FREQ=DAILY;UNTIL=20240126", ""},
+		{"20240101", "RRULE:FREQ=MONTHLY;BYDAY=MO,TU,WE,TH,FR;BYSETPOS=-1", "20240131"},
+		{"20240101", "RRULE:FREQ=MONTHLY;BYMONTHDAY=-1", "20240131"},
+		{"20240101", "RRULE:FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU", "20240331"},
+		{"20240229", "RRULE:FREQ=YEARLY", "20280229"},
+		{"20240101", "RRULE:FREQ=DAILY;BYDAY=2TU", ""},
+		{"20240101", "RRULE:FREQ=HOURLY", ""},
+		{"20240101", "RRULE:BYDAY=MO", ""},
+	}
+	for _, v := range tbl {
+		urlPath := fmt.Sprintf("api/nextdate?now=20240126&date=%s&repeat=%s",
+			url.QueryEscape(v.date), url.QueryEscape(v.repeat))
+		get, err := getBody(urlPath)
+		assert.NoError(t, err)
+		next := strings.TrimSpace(string(get))
+		_, err = time.Parse("20060102", next)
+		if err != nil && len(v.want) == 0 {
+			continue
+		}
+		assert.Equal(t, v.want, next, `{%q, %q, %q}`,
+			v.date, v.repeat, v.want)
+	}
+}
+
+func TestRRuleDone(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	now := time.Now()
+	id := addTask(t, task{
+		date:   now.Format(`20060102`),
+		title:  "Проверить RRULE с ограничением COUNT",
+		repeat: "RRULE:FREQ=DAILY;INTERVAL=2;COUNT=2",
+	})
+
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, now.AddDate(0, 0, 2).Format(`20060102`), task.Date)
+
+	ret, err = postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+}
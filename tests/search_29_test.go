@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearch(t *testing.T) {
+	if !Search {
+		t.Skip("Поиск задач не реализован")
+	}
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	today := time.Now().Format(`20060102`)
+	add := func(title, comment string) string {
+		m, code, err := requestAs(Token, http.MethodPost, "api/task", map[string]any{
+			"date":    today,
+			"title":   title + " " + suffix,
+			"comment": comment,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, code, m["error"])
+		id, _ := m["id"].(string)
+		return id
+	}
+	milk := add("Купить молоко", "")
+	moreMilk := add("Молоко", "молоко")
+	percent := add("Скидка 50%", "")
+	fifty := add("Скидка 50", "")
+	underscore := add("report_2024", "")
+	reportX := add("reportX2024", "")
+	defer func() {
+		for _, id := range []string{milk, moreMilk, percent, fifty, underscore, reportX} {
+			requestAs(Token, http.MethodDelete, "api/task?id="+id, nil)
+		}
+	}()
+
+	search := func(text, params string) ([]string, []string, int) {
+		m, code, err := requestAs(Token, http.MethodGet, "api/tasks?search="+url.QueryEscape(text)+params, nil)
+		assert.NoError(t, err)
+		var ids, snippets []string
+		tasks, _ := m["tasks"].([]any)
+		for _, v := range tasks {
+			task := v.(map[string]any)
+			ids = append(ids, task["id"].(string))
+			snippet, _ := task["snippet"].(string)
+			snippets = append(snippets, snippet)
+		}
+		return ids, snippets, code
+	}
+
+	// Сортировка по релевантности доступна только при полнотекстовом поиске
+	_, _, code := search(suffix, "&sort=relevance")
+	if code == http.StatusOK {
+		// Из двух задач одинаковой длины первой идёт та, в которой искомое слово
+		// встречается чаще, а найденные слова выделяются во фрагменте
+		ids, snippets, _ := search("молоко "+suffix, "")
+		assert.Equal(t, []string{moreMilk, milk}, ids)
+		for _, snippet := range snippets {
+			assert.Contains(t, strings.ToLower(snippet), "<mark>молоко</mark>")
+		}
+
+		// Слова ищутся как префиксы в любом порядке
+		ids, _, _ = search(suffix+" скид", "&sort=id")
+		assert.Equal(t, []string{percent, fifty}, ids)
+		return
+	}
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	// Без FTS5 задачи ищутся по подстроке без фрагментов
+	ids, snippets, code := search("молоко "+suffix, "")
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, []string{milk}, ids)
+	assert.Equal(t, []string{""}, snippets)
+
+	// Символы % и _ в строке поиска не считаются шаблонами LIKE
+	ids, _, _ = search("50% "+suffix, "")
+	assert.Equal(t, []string{percent}, ids)
+	ids, _, _ = search("report_2024 "+suffix, "")
+	assert.Equal(t, []string{underscore}, ids)
+	ids, _, _ = search(`\`+suffix, "")
+	assert.Empty(t, ids)
+}
@@ -0,0 +1,190 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"go_final_project/database"
+	"go_final_project/handlers"
+)
+
+// memoryServer запускает обработчики задач поверх хранилища в памяти без аутентификации
+func memoryServer() *httptest.Server {
+	store := database.NewMemoryStore()
+	auth := handlers.Auth(nil, "")
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/task", auth(handlers.TaskHandler(store)))
+	mux.HandleFunc("/api/tasks", auth(handlers.GetTasks(store)))
+	mux.HandleFunc("/api/task/done", auth(handlers.HandlePostTaskDone(store)))
+	mux.HandleFunc("/api/task/exceptions", auth(handlers.ExceptionsHandler(store)))
+	return httptest.NewServer(mux)
+}
+
+func memoryRequest(t *testing.T, server *httptest.Server, method, path string, values map[string]any) (map[string]any, int) {
+	t.Helper()
+	var body bytes.Buffer
+	if values != nil {
+		assert.NoError(t, json.NewEncoder(&body).Encode(values))
+	}
+	req, err := http.NewRequest(method, server.URL+"/"+path, &body)
+	assert.NoError(t, err)
+	resp, err := server.Client().Do(req)
+	assert.NoError(t, err)
+	defer resp.Body.Close()
+
+	var m map[string]any
+	assert.NoError(t, json.NewDecoder(resp.Body).Decode(&m))
+	return m, resp.StatusCode
+}
+
+func TestMemoryStoreTasks(t *testing.T) {
+	server := memoryServer()
+	defer server.Close()
+
+	now := time.Now()
+	today := now.Format(`20060102`)
+	m, code := memoryRequest(t, server, http.MethodPost, "api/task", map[string]any{
+		"date":    today,
+		"title":   "Задача в памяти",
+		"comment": "Комментарий",
+		"repeat":  "d 3",
+	})
+	assert.Equal(t, http.StatusOK, code, m["error"])
+	id, _ := m["id"].(string)
+	assert.NotEmpty(t, id)
+
+	m, code = memoryRequest(t, server, http.MethodGet, "api/task?id="+id, nil)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "Задача в памяти", m["title"])
+	assert.Equal(t, today, m["date"])
+
+	m, code = memoryRequest(t, server, http.MethodPut, "api/task", map[string]any{
+		"id":     id,
+		"date":   today,
+		"title":  "Изменённая задача",
+		"repeat": "d 3",
+	})
+	assert.Equal(t, http.StatusOK, code, m["error"])
+
+	// Выполнение повторяющейся задачи переносит её на следующую дату
+	_, code = memoryRequest(t, server, http.MethodPost, "api/task/done?id="+id, nil)
+	assert.Equal(t, http.StatusOK, code)
+	m, _ = memoryRequest(t, server, http.MethodGet, "api/task?id="+id, nil)
+	assert.Equal(t, "Изменённая задача", m["title"])
+	assert.Equal(t, now.AddDate(0, 0, 3).Format(`20060102`), m["date"])
+	assert.Equal(t, "1", m["done_count"])
+
+	_, code = memoryRequest(t, server, http.MethodDelete, "api/task?id="+id, nil)
+	assert.Equal(t, http.StatusOK, code)
+	_, code = memoryRequest(t, server, http.MethodGet, "api/task?id="+id, nil)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	// Одноразовая задача после выполнения перемещается в архив
+	m, _ = memoryRequest(t, server, http.MethodPost, "api/task", map[string]any{"date": today, "title": "Разовая"})
+	id, _ = m["id"].(string)
+	_, code = memoryRequest(t, server, http.MethodPost, "api/task/done?id="+id, nil)
+	assert.Equal(t, http.StatusOK, code)
+	_, code = memoryRequest(t, server, http.MethodGet, "api/task?id="+id, nil)
+	assert.Equal(t, http.StatusNotFound, code)
+}
+
+func TestMemoryStorePagination(t *testing.T) {
+	server := memoryServer()
+	defer server.Close()
+
+	now := time.Now()
+	var ids []string
+	for i := 0; i < 5; i++ {
+		m, code := memoryRequest(t, server, http.MethodPost, "api/task", map[string]any{
+			"date":  now.AddDate(0, 0, 4-i).Format(`20060102`),
+			"title": fmt.Sprintf("Задача %d", i),
+		})
+		assert.Equal(t, http.StatusOK, code, m["error"])
+		ids = append([]string{m["id"].(string)}, ids...)
+	}
+
+	// Задачи упорядочены по дате, курсор ведёт на следующую страницу
+	var all []string
+	params := "limit=2"
+	for pages := 0; pages < 5; pages++ {
+		m, code := memoryRequest(t, server, http.MethodGet, "api/tasks?"+params, nil)
+		if !assert.Equal(t, http.StatusOK, code, m["error"]) {
+			return
+		}
+		assert.Equal(t, float64(5), m["total"])
+		for _, v := range m["tasks"].([]any) {
+			all = append(all, v.(map[string]any)["id"].(string))
+		}
+		cursor, ok := m["next_cursor"].(string)
+		if !ok {
+			break
+		}
+		params = "limit=2&cursor=" + cursor
+	}
+	assert.Equal(t, ids, all)
+
+	m, _ := memoryRequest(t, server, http.MethodGet, "api/tasks?search="+url.QueryEscape("задача 3"), nil)
+	assert.Len(t, m["tasks"], 1)
+}
+
+func TestMemoryStoreExceptions(t *testing.T) {
+	server := memoryServer()
+	defer server.Close()
+
+	now := time.Now()
+	today := now.Format(`20060102`)
+	m, code := memoryRequest(t, server, http.MethodPost, "api/task", map[string]any{
+		"date":   today,
+		"title":  "Планёрка",
+		"repeat": "d 1",
+	})
+	assert.Equal(t, http.StatusOK, code, m["error"])
+	id, _ := m["id"].(string)
+
+	// Отмена текущего повторения переносит задачу на следующий день
+	m, code = memoryRequest(t, server, http.MethodPost, "api/task/exceptions", map[string]any{
+		"task_id": id,
+		"date":    today,
+		"skip":    true,
+	})
+	assert.Equal(t, http.StatusOK, code, m["error"])
+	skipID, _ := m["id"].(string)
+	tomorrow := now.AddDate(0, 0, 1).Format(`20060102`)
+	m, _ = memoryRequest(t, server, http.MethodGet, "api/task?id="+id, nil)
+	assert.Equal(t, tomorrow, m["date"])
+
+	// Перенос повторения меняет дату и заголовок задачи в списке
+	later := now.AddDate(0, 0, 3).Format(`20060102`)
+	m, code = memoryRequest(t, server, http.MethodPost, "api/task/exceptions", map[string]any{
+		"task_id":  id,
+		"date":     tomorrow,
+		"new_date": later,
+		"title":    "Перенесённая планёрка",
+	})
+	assert.Equal(t, http.StatusOK, code, m["error"])
+	m, _ = memoryRequest(t, server, http.MethodGet, "api/tasks", nil)
+	tasks, _ := m["tasks"].([]any)
+	if assert.Len(t, tasks, 1) {
+		task := tasks[0].(map[string]any)
+		assert.Equal(t, later, task["date"])
+		assert.Equal(t, "Перенесённая планёрка", task["title"])
+	}
+
+	m, _ = memoryRequest(t, server, http.MethodGet, "api/task/exceptions?id="+id, nil)
+	assert.Len(t, m["exceptions"], 2)
+	_, code = memoryRequest(t, server, http.MethodDelete, "api/task/exceptions?id="+skipID, nil)
+	assert.Equal(t, http.StatusOK, code)
+	_, code = memoryRequest(t, server, http.MethodDelete, "api/task/exceptions?id="+skipID, nil)
+	assert.Equal(t, http.StatusNotFound, code)
+	m, _ = memoryRequest(t, server, http.MethodGet, "api/task/exceptions?id="+id, nil)
+	assert.Len(t, m["exceptions"], 1)
+}
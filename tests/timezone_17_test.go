@@ -0,0 +1,82 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTaskTimezone(t *testing.T) {
+	loc, err := time.LoadLocation("Asia/Vladivostok")
+	assert.NoError(t, err)
+	today := time.Now().In(loc)
+
+	ret, err := postJSON("api/task", map[string]any{
+		"date":     today.Format(`20060102`),
+		"title":    "Созвон",
+		"time":     "23:59",
+		"timezone": "Asia/Vladivostok",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id, _ := ret["id"].(string)
+	assert.NotEmpty(t, id)
+
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]string
+	assert.NoError(t, json.Unmarshal(body, &m))
+
+	due := time.Date(today.Year(), today.Month(), today.Day(), 23, 59, 0, 0, loc)
+	assert.Equal(t, today.Format(`20060102`), m["date"])
+	assert.Equal(t, "23:59", m["time"])
+	assert.Equal(t, "Asia/Vladivostok", m["timezone"])
+	assert.Equal(t, due.Format(time.RFC3339), m["due_local"])
+	assert.Equal(t, due.UTC().Format(time.RFC3339), m["due_utc"])
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
+
+func TestTaskTimePassed(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	// Время 00:00 по UTC уже прошло, поэтому ежедневная задача переносится на завтра
+	today := time.Now().UTC()
+	ret, err := postJSON("api/task", map[string]any{
+		"date":     today.Format(`20060102`),
+		"title":    "Утренняя зарядка",
+		"repeat":   "d 1",
+		"time":     "00:00",
+		"timezone": "UTC",
+	}, http.MethodPost)
+	assert.NoError(t, err)
+	id, _ := ret["id"].(string)
+	assert.NotEmpty(t, id)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, today.AddDate(0, 0, 1).Format(`20060102`), task.Date)
+	assert.Equal(t, "00:00", task.Time)
+	assert.Equal(t, "UTC", task.Timezone)
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+
+	tbl := []map[string]any{
+		{"date": today.Format(`20060102`), "title": "Неверное время", "time": "25:00"},
+		{"date": today.Format(`20060102`), "title": "Неверный пояс", "timezone": "Mars/Olympus"},
+	}
+	for _, v := range tbl {
+		ret, err := postJSON("api/task", v, http.MethodPost)
+		assert.NoError(t, err)
+		e, ok := ret["error"]
+		assert.True(t, ok && len(e.(string)) > 0, "Ожидается ошибка для задачи %v", v)
+	}
+}
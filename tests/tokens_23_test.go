@@ -0,0 +1,86 @@
+package tests
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPITokens(t *testing.T) {
+	if os.Getenv("TODO_PASSWORD") == "" {
+		t.Skip("Сервер запущен без пароля")
+	}
+
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	user := signup(t, "user"+suffix, "password")
+
+	newToken := func(token, name, scope string) (string, string, int) {
+		m, code, err := requestAs(token, http.MethodPost, "api/tokens", map[string]any{"name": name, "scope": scope})
+		assert.NoError(t, err)
+		id, _ := m["id"].(string)
+		value, _ := m["token"].(string)
+		return id, value, code
+	}
+	readID, read, code := newToken(user, "Отчёты", "read")
+	assert.Equal(t, http.StatusOK, code)
+	assert.True(t, strings.HasPrefix(read, "todo_"))
+	_, write, code := newToken(user, "CI", "write")
+	assert.Equal(t, http.StatusOK, code)
+	_, _, code = newToken(user, "", "read")
+	assert.Equal(t, http.StatusBadRequest, code)
+	_, _, code = newToken(user, "Всё", "root")
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	task := map[string]any{
+		"date":  time.Now().Format(`20060102`),
+		"title": "Задача из CI " + suffix,
+	}
+	_, code, err := requestAs(read, http.MethodPost, "api/task", task)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusForbidden, code)
+	m, code, err := requestAs(write, http.MethodPost, "api/task", task)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	id, _ := m["id"].(string)
+
+	// Задача, созданная по токену, принадлежит его владельцу
+	m, code, err = requestAs(read, http.MethodGet, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, task["title"], m["title"])
+
+	// Управлять токенами токен области write не может
+	_, _, code = newToken(write, "Ещё", "admin")
+	assert.Equal(t, http.StatusForbidden, code)
+
+	m, code, err = requestAs(user, http.MethodGet, "api/tokens", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	if tokens, ok := m["tokens"].([]any); assert.True(t, ok) && assert.Len(t, tokens, 2) {
+		first := tokens[0].(map[string]any)
+		assert.Equal(t, "Отчёты", first["name"])
+		assert.Equal(t, "read", first["scope"])
+		assert.Nil(t, first["token"])
+		assert.NotEmpty(t, first["last_used_at"])
+	}
+
+	// Отозванный токен больше не действует
+	_, code, err = requestAs(user, http.MethodDelete, "api/tokens?id="+readID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	_, code, err = requestAs(read, http.MethodGet, "api/tasks", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+	_, code, err = requestAs(user, http.MethodDelete, "api/tokens?id="+readID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	_, code, err = requestAs(write, http.MethodDelete, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+}
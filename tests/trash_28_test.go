@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func trashIDs(t *testing.T, params string) []string {
+	body, err := requestJSON("api/trash"+params, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m struct {
+		Tasks []map[string]string `json:"tasks"`
+	}
+	assert.NoError(t, json.Unmarshal(body, &m))
+	var ids []string
+	for _, task := range m.Tasks {
+		ids = append(ids, task["id"])
+	}
+	return ids
+}
+
+func TestTrash(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	id := addTask(t, task{
+		date:  time.Now().Format(`20060102`),
+		title: "Случайно удалённая задача",
+	})
+
+	// Удалённая задача попадает в корзину и пропадает из списка задач
+	ret, err := postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+	assert.Contains(t, trashIDs(t, ""), id)
+
+	var task Task
+	err = db.Get(&task, `SELECT * FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	_, err = time.Parse(time.RFC3339, task.DeletedAt)
+	assert.NoError(t, err)
+
+	// Восстановленная задача возвращается в список задач
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.NotContains(t, trashIDs(t, ""), id)
+	body, err := requestJSON("api/task?id="+id, nil, http.MethodGet)
+	assert.NoError(t, err)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal(body, &m))
+	assert.Equal(t, "Случайно удалённая задача", m["title"])
+
+	// Активную задачу нельзя восстановить или удалить окончательно
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.NotEmpty(t, ret["error"])
+
+	// Окончательное удаление стирает задачу из базы
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	ret, err = postJSON("api/trash?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.NotContains(t, trashIDs(t, ""), id)
+	var count int
+	err = db.Get(&count, `SELECT count(*) FROM scheduler WHERE id=?`, id)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, count)
+}
+
+func TestArchive(t *testing.T) {
+	id := addTask(t, task{
+		date:  time.Now().Format(`20060102`),
+		title: "Выполненная задача",
+	})
+	defer postJSON("api/trash?id="+id, nil, http.MethodDelete)
+
+	// Выполненная одноразовая задача переносится в архив, а не в корзину
+	ret, err := postJSON("api/task/done?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	notFoundTask(t, id)
+	assert.Contains(t, trashIDs(t, "?archived=true"), id)
+	assert.NotContains(t, trashIDs(t, ""), id)
+
+	ret, err = postJSON("api/trash/restore?id="+id, nil, http.MethodPost)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+	assert.NotContains(t, trashIDs(t, "?archived=true"), id)
+
+	ret, err = postJSON("api/task?id="+id, nil, http.MethodDelete)
+	assert.NoError(t, err)
+	assert.Empty(t, ret)
+}
+
+// TestTrashOrder проверяет, что корзина и архив начинаются с задачи,
+// перемещённой последней, даже если она создана раньше
+func TestTrashOrder(t *testing.T) {
+	db := openDB(t)
+	defer db.Close()
+
+	for _, v := range []struct {
+		column string
+		params string
+		action string
+	}{
+		{"deleted_at", "", "api/task?id="},
+		{"archived_at", "?archived=true", "api/task/done?id="},
+	} {
+		var ids []string
+		for _, title := range []string{"Перемещена последней", "Перемещена первой"} {
+			id := addTask(t, task{
+				date:  time.Now().Format(`20060102`),
+				title: title,
+			})
+			defer postJSON("api/trash?id="+id, nil, http.MethodDelete)
+			method := http.MethodPost
+			if v.column == "deleted_at" {
+				method = http.MethodDelete
+			}
+			ret, err := postJSON(v.action+id, nil, method)
+			assert.NoError(t, err)
+			assert.Empty(t, ret)
+			ids = append(ids, id)
+		}
+
+		now := time.Now().UTC()
+		for i, id := range ids {
+			movedAt := now.Add(-time.Duration(i) * time.Minute).Format(time.RFC3339)
+			_, err := db.Exec(`UPDATE scheduler SET `+v.column+` = ? WHERE id = ?`, movedAt, id)
+			assert.NoError(t, err)
+		}
+
+		trash := trashIDs(t, v.params)
+		position := map[string]int{}
+		for i, id := range trash {
+			position[id] = i
+		}
+		assert.Less(t, position[ids[0]], position[ids[1]], "%s: %v", v.column, trash)
+	}
+}
@@ -0,0 +1,135 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// requestAs выполняет запрос к API с токеном указанного пользователя
+func requestAs(token, method, apipath string, values map[string]any) (map[string]any, int, error) {
+	var data []byte
+	if values != nil {
+		var err error
+		data, err = json.Marshal(values)
+		if err != nil {
+			return nil, 0, err
+		}
+	}
+	req, err := http.NewRequest(method, getURL(apipath), bytes.NewBuffer(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	var m map[string]any
+	if err := json.NewDecoder(resp.Body).Decode(&m); err != nil {
+		return nil, resp.StatusCode, err
+	}
+	return m, resp.StatusCode, nil
+}
+
+func signup(t *testing.T, login, password string) string {
+	m, code, err := requestAs("", http.MethodPost, "api/signup", map[string]any{"login": login, "password": password})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code, m["error"])
+	token, _ := m["token"].(string)
+	assert.NotEmpty(t, token)
+	assert.Equal(t, "user", m["role"])
+	return token
+}
+
+func TestUsers(t *testing.T) {
+	if os.Getenv("TODO_PASSWORD") == "" {
+		t.Skip("Сервер запущен без пароля")
+	}
+
+	// Регистрация через API не даёт роль администратора даже первому пользователю:
+	// signup проверяет, что каждый новый пользователь получает роль user
+	suffix := fmt.Sprint(time.Now().UnixNano())
+	first := signup(t, "first"+suffix, "password")
+	alice := signup(t, "alice"+suffix, "password")
+	bob := signup(t, "bob"+suffix, "password")
+
+	_, code, err := requestAs("", http.MethodPost, "api/signup", map[string]any{"login": "alice" + suffix, "password": "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusConflict, code)
+	_, code, err = requestAs("", http.MethodPost, "api/signup", map[string]any{"login": "carol" + suffix, "password": "123"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadRequest, code)
+
+	m, code, err := requestAs("", http.MethodPost, "api/signin", map[string]any{"login": "alice" + suffix, "password": "password"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "user", m["role"])
+	_, code, err = requestAs("", http.MethodPost, "api/signin", map[string]any{"login": "alice" + suffix, "password": "wrong"})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusUnauthorized, code)
+
+	title := "Задача " + suffix
+	m, code, err = requestAs(alice, http.MethodPost, "api/task", map[string]any{
+		"date":  time.Now().Format(`20060102`),
+		"title": title,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	id, _ := m["id"].(string)
+	assert.NotEmpty(t, id)
+
+	taskTitles := func(token string) []string {
+		m, _, err := requestAs(token, http.MethodGet, "api/tasks?search="+suffix, nil)
+		assert.NoError(t, err)
+		var titles []string
+		tasks, _ := m["tasks"].([]any)
+		for _, v := range tasks {
+			titles = append(titles, v.(map[string]any)["title"].(string))
+		}
+		return titles
+	}
+	assert.Equal(t, []string{title}, taskTitles(alice))
+	assert.Empty(t, taskTitles(bob))
+	assert.Empty(t, taskTitles(first))
+	// Администратор видит задачи всех пользователей
+	assert.Equal(t, []string{title}, taskTitles(Token))
+
+	// Чужая задача для пользователя не отличается от несуществующей
+	_, code, err = requestAs(bob, http.MethodGet, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+	_, code, err = requestAs(bob, http.MethodPut, "api/task", map[string]any{
+		"id":    id,
+		"date":  time.Now().Format(`20060102`),
+		"title": "Чужая задача",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+	_, code, err = requestAs(bob, http.MethodPost, "api/task/done?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	_, code, err = requestAs(bob, http.MethodDelete, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusNotFound, code)
+
+	m, code, err = requestAs(alice, http.MethodGet, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, title, m["title"])
+
+	_, code, err = requestAs(alice, http.MethodDelete, "api/task?id="+id, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+}
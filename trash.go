@@ -0,0 +1,46 @@
+package main
+
+import (
+	"errors"
+	"go_final_project/database"
+	"log"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Срок хранения задач в корзине по умолчанию и период проверки истёкших задач
+const (
+	defaultTrashDays   = 30
+	purgeTrashInterval = time.Hour
+)
+
+// trashRetention возвращает срок хранения задач в корзине из TODO_TRASH_DAYS;
+// 0 — задачи хранятся, пока их не удалят вручную
+func trashRetention() (time.Duration, error) {
+	days := defaultTrashDays
+	if value := os.Getenv("TODO_TRASH_DAYS"); value != "" {
+		var err error
+		days, err = strconv.Atoi(value)
+		if err != nil || days < 0 {
+			return 0, errors.New("TODO_TRASH_DAYS должна быть неотрицательным числом дней")
+		}
+	}
+	return time.Duration(days) * 24 * time.Hour, nil
+}
+
+// purgeTrash окончательно удаляет задачи, которые пролежали в корзине дольше retention.
+// Несколько экземпляров приложения с общей базой могут очищать корзину одновременно.
+func purgeTrash(store database.TaskStore, retention time.Duration) {
+	ticker := time.NewTicker(purgeTrashInterval)
+	defer ticker.Stop()
+	for {
+		purged, err := store.PurgeDeleted(time.Now().Add(-retention))
+		if err != nil {
+			log.Printf("Ошибка при очистке корзины: %v", err)
+		} else if purged > 0 {
+			log.Printf("Из корзины окончательно удалено задач: %d", purged)
+		}
+		<-ticker.C
+	}
+}
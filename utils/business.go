@@ -0,0 +1,111 @@
+package utils
+
+import (
+	"go_final_project/calendar"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// BusinessDayRule описывает правило "b <число>" — повторение через указанное
+// количество рабочих дней с учётом выходных и праздников календаря
+type BusinessDayRule struct {
+	Days int
+}
+
+// BusinessMonthRule описывает правило "bm <номера>" — повторение в указанные
+// по счёту рабочие дни месяца, -1 — последний рабочий день
+type BusinessMonthRule struct {
+	Days []int
+}
+
+func parseBusinessDayRule(spec string) (BusinessDayRule, error) {
+	days, err := strconv.Atoi(spec)
+	if err != nil {
+		return BusinessDayRule{}, ruleError(ErrMalformedRule, "ожидается \"b <число>\"")
+	}
+	if days < 1 || days > 400 {
+		return BusinessDayRule{}, ruleError(ErrOutOfRange, "интервал %d рабочих дней, допустимо от 1 до 400", days)
+	}
+	return BusinessDayRule{Days: days}, nil
+}
+
+// ParseBusinessMonthRule разбирает часть правила после "bm "
+func ParseBusinessMonthRule(spec string) (BusinessMonthRule, error) {
+	var rule BusinessMonthRule
+	parts := strings.Fields(spec)
+	if len(parts) != 1 {
+		return rule, ruleError(ErrMalformedRule, "ожидается \"bm <номера рабочих дней>\"")
+	}
+
+	days, err := parseIntList(parts[0])
+	if err != nil {
+		return rule, err
+	}
+	for _, day := range days {
+		// В месяце не больше 23 рабочих дней
+		if day == 0 || day < -23 || day > 23 {
+			return rule, ruleError(ErrOutOfRange, "рабочий день месяца %d", day)
+		}
+	}
+	rule.Days = days
+	return rule, nil
+}
+
+// Next прибавляет рабочие дни к дате задачи, пока следующая дата не станет больше текущей
+func (r BusinessDayRule) Next(now, date time.Time) (time.Time, error) {
+	cal := calendar.Default()
+	nextDate := cal.AddWorkdays(date, r.Days)
+	for nextDate.Before(now) || nextDate.Equal(now) {
+		nextDate = cal.AddWorkdays(nextDate, r.Days)
+	}
+	return checkCalendar(cal, nextDate)
+}
+
+// Next возвращает первый подходящий под правило рабочий день после даты задачи и текущей даты
+func (r BusinessMonthRule) Next(now, date time.Time) (time.Time, error) {
+	nextDate, err := findNext(searchStart(now, date), r.Match)
+	if err != nil {
+		return nextDate, err
+	}
+	return checkCalendar(calendar.Default(), nextDate)
+}
+
+// checkCalendar возвращает ErrOutsideCalendar, если за год следующей даты в календаре
+// нет данных: без них праздник был бы принят за рабочий день
+func checkCalendar(cal *calendar.Calendar, nextDate time.Time) (time.Time, error) {
+	if !cal.Covers(nextDate) {
+		return time.Time{}, ruleError(ErrOutsideCalendar, "нет данных за %d год", nextDate.Year())
+	}
+	return nextDate, nil
+}
+
+// Match проверяет, является ли дата рабочим днём месяца с одним из указанных номеров
+func (r BusinessMonthRule) Match(t time.Time) bool {
+	cal := calendar.Default()
+	if !cal.IsWorkday(t) {
+		return false
+	}
+
+	// Номер рабочего дня с начала месяца и с конца месяца
+	fromStart, fromEnd := 0, 0
+	for d := 1; d <= daysIn(t.Year(), t.Month()); d++ {
+		day := time.Date(t.Year(), t.Month(), d, 0, 0, 0, 0, t.Location())
+		if !cal.IsWorkday(day) {
+			continue
+		}
+		if d <= t.Day() {
+			fromStart++
+		}
+		if d >= t.Day() {
+			fromEnd++
+		}
+	}
+
+	for _, day := range r.Days {
+		if day == fromStart || day == -fromEnd {
+			return true
+		}
+	}
+	return false
+}
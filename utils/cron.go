@@ -0,0 +1,169 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+var cronMonthNames = map[string]int{
+	"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6,
+	"JUL": 7, "AUG": 8, "SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12,
+}
+
+var cronWeekdayNames = map[string]int{
+	"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6,
+}
+
+// cronMacros — сокращённые записи расписаний, кратные дню
+var cronMacros = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+}
+
+// CronRule описывает cron-выражение из пяти полей "минуты часы дни месяцы дни_недели".
+// Задачи планируются с точностью до дня, поэтому минуты и часы только проверяются.
+type CronRule struct {
+	MonthDays   [32]bool
+	Months      [13]bool
+	Weekdays    [7]bool
+	AnyMonthDay bool // поле дней месяца начинается с "*"
+	AnyWeekday  bool // поле дней недели начинается с "*"
+}
+
+// IsCronRule проверяет, похожа ли строка повторения на cron-выражение
+func IsCronRule(repeatStr string) bool {
+	return strings.HasPrefix(repeatStr, "@") || len(strings.Fields(repeatStr)) == 5
+}
+
+// ParseCronRule разбирает cron-выражение или макрос вида "@weekly"
+func ParseCronRule(spec string) (CronRule, error) {
+	var rule CronRule
+	if strings.HasPrefix(spec, "@") {
+		expanded, ok := cronMacros[strings.ToLower(spec)]
+		if !ok {
+			return rule, ruleError(ErrUnknownRule, "макрос cron %s", spec)
+		}
+		spec = expanded
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return rule, ruleError(ErrMalformedRule, "cron-выражение должно содержать 5 полей")
+	}
+
+	if _, err := parseCronField(fields[0], 0, 59, nil); err != nil {
+		return rule, err
+	}
+	if _, err := parseCronField(fields[1], 0, 23, nil); err != nil {
+		return rule, err
+	}
+
+	monthDays, err := parseCronField(fields[2], 1, 31, nil)
+	if err != nil {
+		return rule, err
+	}
+	months, err := parseCronField(fields[3], 1, 12, cronMonthNames)
+	if err != nil {
+		return rule, err
+	}
+	weekdays, err := parseCronField(fields[4], 0, 7, cronWeekdayNames)
+	if err != nil {
+		return rule, err
+	}
+
+	for _, day := range monthDays {
+		rule.MonthDays[day] = true
+	}
+	for _, month := range months {
+		rule.Months[month] = true
+	}
+	for _, weekday := range weekdays {
+		// 0 и 7 — воскресенье
+		rule.Weekdays[weekday%7] = true
+	}
+	rule.AnyMonthDay = strings.HasPrefix(fields[2], "*")
+	rule.AnyWeekday = strings.HasPrefix(fields[4], "*")
+
+	return rule, nil
+}
+
+// Next возвращает первый подходящий под правило день после даты задачи и текущей даты
+func (r CronRule) Next(now, date time.Time) (time.Time, error) {
+	return findNext(searchStart(now, date), r.Match)
+}
+
+// Match проверяет, попадает ли дата под правило. Как и в cron, если ограничены
+// и дни месяца, и дни недели, достаточно совпадения любого из них.
+func (r CronRule) Match(t time.Time) bool {
+	if !r.Months[t.Month()] {
+		return false
+	}
+	monthDay := r.MonthDays[t.Day()]
+	weekday := r.Weekdays[t.Weekday()]
+	if !r.AnyMonthDay && !r.AnyWeekday {
+		return monthDay || weekday
+	}
+	return monthDay && weekday
+}
+
+// parseCronField разбирает поле cron-выражения: списки, диапазоны, шаги и имена
+func parseCronField(field string, min, max int, names map[string]int) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(field, ",") {
+		rangePart, stepPart, hasStep := strings.Cut(item, "/")
+		step := 1
+		if hasStep {
+			var err error
+			step, err = strconv.Atoi(stepPart)
+			if err != nil || step < 1 {
+				return nil, ruleError(ErrOutOfRange, "шаг в cron-выражении %s", item)
+			}
+		}
+
+		var from, to int
+		if rangePart == "*" {
+			from, to = min, max
+		} else {
+			fromStr, toStr, isRange := strings.Cut(rangePart, "-")
+			var err error
+			from, err = parseCronValue(fromStr, min, max, names)
+			if err != nil {
+				return nil, err
+			}
+			to = from
+			if isRange {
+				to, err = parseCronValue(toStr, min, max, names)
+				if err != nil {
+					return nil, err
+				}
+			} else if hasStep {
+				// "5/10" означает "с 5 до конца диапазона с шагом 10"
+				to = max
+			}
+			if from > to {
+				return nil, ruleError(ErrMalformedRule, "диапазон в cron-выражении %s", item)
+			}
+		}
+
+		for value := from; value <= to; value += step {
+			values = append(values, value)
+		}
+	}
+	return values, nil
+}
+
+func parseCronValue(s string, min, max int, names map[string]int) (int, error) {
+	if value, ok := names[strings.ToUpper(s)]; ok {
+		return value, nil
+	}
+	value, err := strconv.Atoi(s)
+	if err != nil || value < min || value > max {
+		return 0, ruleError(ErrOutOfRange, "значение в cron-выражении %s", s)
+	}
+	return value, nil
+}
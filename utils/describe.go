@@ -0,0 +1,22 @@
+package utils
+
+import "strings"
+
+// Языки описаний правил повторения
+const (
+	LangRu = "ru"
+	LangEn = "en"
+)
+
+// DescribeRepeat возвращает описание правила повторения на естественном языке.
+// Поддерживаются русский и английский языки, для остальных используется русский.
+func DescribeRepeat(repeatStr string, lang string) (string, error) {
+	rule, err := ParseRepeat(repeatStr)
+	if err != nil {
+		return "", err
+	}
+	if strings.HasPrefix(strings.ToLower(lang), LangEn) {
+		return describeEn(rule), nil
+	}
+	return describeRu(rule), nil
+}
@@ -0,0 +1,226 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func describeEn(rule Rule) string {
+	switch r := rule.(type) {
+	case DayRule:
+		return enInterval(r.Days, "every day", "days")
+	case YearRule:
+		return "every year"
+	case MonthRule:
+		return "on the " + enMonthDays(r.Days) + " day of " + enMonthsOf(r.Months)
+	case WeekRule:
+		return "every " + enJoin(enWeekdayList(r.Weekdays))
+	case BusinessDayRule:
+		return enInterval(r.Days, "every business day", "business days")
+	case BusinessMonthRule:
+		return "on the " + enMonthDays(r.Days) + " business day of the month"
+	case MonthWeekdayRule:
+		var items []string
+		for _, day := range r.Weekdays {
+			items = append(items, enOrdinalWeekday(day))
+		}
+		return "on the " + enJoin(items) + " of " + enMonthsOf(r.Months)
+	case RRule:
+		return enRRule(r)
+	case CronRule:
+		return enCron(r)
+	}
+	return ""
+}
+
+// enMonthDays описывает дни месяца: "1st and 15th", "last and 15th"
+func enMonthDays(days []int) string {
+	var items []string
+	for _, day := range days {
+		switch {
+		case day == -1:
+			items = append(items, "last")
+		case day == -2:
+			items = append(items, "second-to-last")
+		case day < 0:
+			items = append(items, enOrdinal(-day)+"-to-last")
+		default:
+			items = append(items, enOrdinal(day))
+		}
+	}
+	return enJoin(items)
+}
+
+// enMonthsOf описывает месяцы: "March and June" или "the month"
+func enMonthsOf(months []time.Month) string {
+	if len(months) == 0 {
+		return "the month"
+	}
+	return enJoin(enMonthList(months))
+}
+
+func enMonthList(months []time.Month) []string {
+	var items []string
+	for _, month := range months {
+		items = append(items, month.String())
+	}
+	return items
+}
+
+func enWeekdayList(weekdays []time.Weekday) []string {
+	var items []string
+	for _, weekday := range weekdays {
+		items = append(items, weekday.String())
+	}
+	return items
+}
+
+func enRRule(r RRule) string {
+	var text string
+	switch r.Freq {
+	case FreqDaily:
+		text = enInterval(r.Interval, "daily", "days")
+	case FreqWeekly:
+		text = enInterval(r.Interval, "weekly", "weeks")
+	case FreqMonthly:
+		text = enInterval(r.Interval, "monthly", "months")
+	case FreqYearly:
+		text = enInterval(r.Interval, "yearly", "years")
+	}
+
+	var parts []string
+	if len(r.ByDay) > 0 {
+		var plain []time.Weekday
+		var ordinal []string
+		for _, day := range r.ByDay {
+			if day.N == 0 {
+				plain = append(plain, day.Weekday)
+				continue
+			}
+			ordinal = append(ordinal, enOrdinalWeekday(day))
+		}
+		if len(plain) > 0 {
+			parts = append(parts, "on "+enJoin(enWeekdayList(plain)))
+		}
+		if len(ordinal) > 0 {
+			parts = append(parts, "on the "+enJoin(ordinal))
+		}
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "on the "+enMonthDays(r.ByMonthDay)+" day of the month")
+	}
+	if len(r.ByMonth) > 0 {
+		parts = append(parts, "in "+enJoin(enMonthList(r.ByMonth)))
+	}
+	if len(r.BySetPos) > 0 {
+		var positions []string
+		for _, pos := range r.BySetPos {
+			positions = append(positions, strconv.Itoa(pos))
+		}
+		parts = append(parts, "positions in period: "+strings.Join(positions, ", "))
+	}
+	if r.Count == 1 {
+		parts = append(parts, "once")
+	} else if r.Count > 1 {
+		parts = append(parts, fmt.Sprintf("%d times", r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "until "+r.Until.Format("January 2, 2006"))
+	}
+
+	if len(parts) == 0 {
+		return text
+	}
+	return text + ", " + strings.Join(parts, ", ")
+}
+
+// enOrdinalWeekday описывает элемент BYDAY с номером: "2nd Tuesday", "last Friday"
+func enOrdinalWeekday(day WeekdayNum) string {
+	switch {
+	case day.N == -1:
+		return "last " + day.Weekday.String()
+	case day.N < 0:
+		return enOrdinal(-day.N) + "-to-last " + day.Weekday.String()
+	default:
+		return enOrdinal(day.N) + " " + day.Weekday.String()
+	}
+}
+
+func enCron(r CronRule) string {
+	var parts []string
+	if !r.AnyMonthDay {
+		var days []int
+		for day := 1; day <= 31; day++ {
+			if r.MonthDays[day] {
+				days = append(days, day)
+			}
+		}
+		parts = append(parts, "on the "+enMonthDays(days)+" day of the month")
+	}
+	if !r.AnyWeekday {
+		var weekdays []time.Weekday
+		// Перечисляем дни недели начиная с понедельника
+		for i := 1; i <= 7; i++ {
+			if r.Weekdays[i%7] {
+				weekdays = append(weekdays, time.Weekday(i%7))
+			}
+		}
+		parts = append(parts, "every "+enJoin(enWeekdayList(weekdays)))
+	}
+
+	var text string
+	switch len(parts) {
+	case 0:
+		text = "every day"
+	case 1:
+		text = parts[0]
+	default:
+		// Как и в cron, достаточно совпадения дня месяца или дня недели
+		text = parts[0] + " or " + parts[1]
+	}
+
+	var months []time.Month
+	for month := time.January; month <= time.December; month++ {
+		if r.Months[month] {
+			months = append(months, month)
+		}
+	}
+	if len(months) < 12 {
+		text += ", in " + enJoin(enMonthList(months))
+	}
+	return text
+}
+
+// enInterval описывает интервал: "monthly" для 1, "every 2 months" для остальных
+func enInterval(n int, every, units string) string {
+	if n == 1 {
+		return every
+	}
+	return fmt.Sprintf("every %d %s", n, units)
+}
+
+// enOrdinal возвращает английское порядковое числительное: 1st, 2nd, 3rd, 11th, 22nd
+func enOrdinal(n int) string {
+	suffix := "th"
+	if n%100 < 11 || n%100 > 13 {
+		switch n % 10 {
+		case 1:
+			suffix = "st"
+		case 2:
+			suffix = "nd"
+		case 3:
+			suffix = "rd"
+		}
+	}
+	return strconv.Itoa(n) + suffix
+}
+
+// enJoin перечисляет элементы через запятую, последний — через "and"
+func enJoin(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " and " + items[len(items)-1]
+}
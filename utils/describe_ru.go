@@ -0,0 +1,244 @@
+package utils
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ruMonthsGenitive = [...]string{"", "января", "февраля", "марта", "апреля", "мая", "июня",
+	"июля", "августа", "сентября", "октября", "ноября", "декабря"}
+
+var ruMonthsPrepositional = [...]string{"", "январе", "феврале", "марте", "апреле", "мае", "июне",
+	"июле", "августе", "сентябре", "октябре", "ноябре", "декабре"}
+
+// ruWeekdaysDative — "по понедельникам", индекс соответствует time.Weekday
+var ruWeekdaysDative = [...]string{"воскресеньям", "понедельникам", "вторникам", "средам",
+	"четвергам", "пятницам", "субботам"}
+
+// ruWeekdays — название дня недели с окончанием порядкового числительного и словом "последний" в нужном роде
+var ruWeekdays = [...]struct{ name, ending, last string }{
+	{"воскресенье", "е", "последнее"}, {"понедельник", "й", "последний"}, {"вторник", "й", "последний"},
+	{"среда", "я", "последняя"}, {"четверг", "й", "последний"}, {"пятница", "я", "последняя"},
+	{"суббота", "я", "последняя"},
+}
+
+func describeRu(rule Rule) string {
+	switch r := rule.(type) {
+	case DayRule:
+		if r.Days == 1 {
+			return "каждый день"
+		}
+		return ruPlural(r.Days, "каждый %d день", "каждые %d дня", "каждые %d дней")
+	case YearRule:
+		return "ежегодно"
+	case MonthRule:
+		return "в " + ruMonthDays(r.Days) + " день " + ruMonthsOf(r.Months)
+	case WeekRule:
+		return "по " + ruJoin(ruWeekdayList(r.Weekdays))
+	case BusinessDayRule:
+		if r.Days == 1 {
+			return "каждый рабочий день"
+		}
+		return ruPlural(r.Days, "каждый %d рабочий день", "каждые %d рабочих дня", "каждые %d рабочих дней")
+	case BusinessMonthRule:
+		return "в " + ruMonthDays(r.Days) + " рабочий день месяца"
+	case MonthWeekdayRule:
+		var items []string
+		for _, day := range r.Weekdays {
+			items = append(items, ruOrdinalWeekday(day))
+		}
+		return ruJoin(items) + " " + ruMonthsOf(r.Months)
+	case RRule:
+		return ruRRule(r)
+	case CronRule:
+		return ruCron(r)
+	}
+	return ""
+}
+
+// ruMonthDays описывает дни месяца: "1-й и 15-й", "последний и 15-й"
+func ruMonthDays(days []int) string {
+	var items []string
+	for _, day := range days {
+		switch {
+		case day == -1:
+			items = append(items, "последний")
+		case day == -2:
+			items = append(items, "предпоследний")
+		case day < 0:
+			items = append(items, strconv.Itoa(-day)+"-й с конца")
+		default:
+			items = append(items, strconv.Itoa(day)+"-й")
+		}
+	}
+	return ruJoin(items)
+}
+
+// ruMonthsOf описывает месяцы в родительном падеже: "марта и июня" или "месяца"
+func ruMonthsOf(months []time.Month) string {
+	if len(months) == 0 {
+		return "месяца"
+	}
+	var items []string
+	for _, month := range months {
+		items = append(items, ruMonthsGenitive[month])
+	}
+	return ruJoin(items)
+}
+
+func ruWeekdayList(weekdays []time.Weekday) []string {
+	var items []string
+	for _, weekday := range weekdays {
+		items = append(items, ruWeekdaysDative[weekday])
+	}
+	return items
+}
+
+func ruRRule(r RRule) string {
+	var text string
+	switch r.Freq {
+	case FreqDaily:
+		text = ruInterval(r.Interval, "ежедневно", "каждый %d день", "каждые %d дня", "каждые %d дней")
+	case FreqWeekly:
+		text = ruInterval(r.Interval, "еженедельно", "каждую %d неделю", "каждые %d недели", "каждые %d недель")
+	case FreqMonthly:
+		text = ruInterval(r.Interval, "ежемесячно", "каждый %d месяц", "каждые %d месяца", "каждые %d месяцев")
+	case FreqYearly:
+		text = ruInterval(r.Interval, "ежегодно", "каждый %d год", "каждые %d года", "каждые %d лет")
+	}
+
+	var parts []string
+	if len(r.ByDay) > 0 {
+		var plain []time.Weekday
+		var ordinal []string
+		for _, day := range r.ByDay {
+			if day.N == 0 {
+				plain = append(plain, day.Weekday)
+				continue
+			}
+			ordinal = append(ordinal, ruOrdinalWeekday(day))
+		}
+		if len(plain) > 0 {
+			parts = append(parts, "по "+ruJoin(ruWeekdayList(plain)))
+		}
+		if len(ordinal) > 0 {
+			parts = append(parts, ruJoin(ordinal))
+		}
+	}
+	if len(r.ByMonthDay) > 0 {
+		parts = append(parts, "в "+ruMonthDays(r.ByMonthDay)+" день месяца")
+	}
+	if len(r.ByMonth) > 0 {
+		var months []string
+		for _, month := range r.ByMonth {
+			months = append(months, ruMonthsPrepositional[month])
+		}
+		parts = append(parts, "в "+ruJoin(months))
+	}
+	if len(r.BySetPos) > 0 {
+		var positions []string
+		for _, pos := range r.BySetPos {
+			positions = append(positions, strconv.Itoa(pos))
+		}
+		parts = append(parts, "позиции в периоде: "+strings.Join(positions, ", "))
+	}
+	if r.Count > 0 {
+		parts = append(parts, ruPlural(r.Count, "%d раз", "%d раза", "%d раз"))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "до "+r.Until.Format("02.01.2006"))
+	}
+
+	if len(parts) == 0 {
+		return text
+	}
+	return text + ", " + strings.Join(parts, ", ")
+}
+
+// ruOrdinalWeekday описывает элемент BYDAY с номером: "2-й вторник", "последняя пятница"
+func ruOrdinalWeekday(day WeekdayNum) string {
+	weekday := ruWeekdays[day.Weekday]
+	switch {
+	case day.N == -1:
+		return weekday.last + " " + weekday.name
+	case day.N < 0:
+		return strconv.Itoa(-day.N) + "-" + weekday.ending + " с конца " + weekday.name
+	default:
+		return strconv.Itoa(day.N) + "-" + weekday.ending + " " + weekday.name
+	}
+}
+
+func ruCron(r CronRule) string {
+	var parts []string
+	if !r.AnyMonthDay {
+		var days []int
+		for day := 1; day <= 31; day++ {
+			if r.MonthDays[day] {
+				days = append(days, day)
+			}
+		}
+		parts = append(parts, "в "+ruMonthDays(days)+" день месяца")
+	}
+	if !r.AnyWeekday {
+		var weekdays []time.Weekday
+		// Перечисляем дни недели начиная с понедельника
+		for i := 1; i <= 7; i++ {
+			if r.Weekdays[i%7] {
+				weekdays = append(weekdays, time.Weekday(i%7))
+			}
+		}
+		parts = append(parts, "по "+ruJoin(ruWeekdayList(weekdays)))
+	}
+
+	var text string
+	switch len(parts) {
+	case 0:
+		text = "ежедневно"
+	case 1:
+		text = parts[0]
+	default:
+		// Как и в cron, достаточно совпадения дня месяца или дня недели
+		text = parts[0] + " или " + parts[1]
+	}
+
+	var months []string
+	for month := 1; month <= 12; month++ {
+		if r.Months[month] {
+			months = append(months, ruMonthsPrepositional[month])
+		}
+	}
+	if len(months) < 12 {
+		text += ", в " + ruJoin(months)
+	}
+	return text
+}
+
+// ruInterval описывает интервал: "ежемесячно" для 1, "каждые 2 месяца" для остальных
+func ruInterval(n int, every, one, few, many string) string {
+	if n == 1 {
+		return every
+	}
+	return ruPlural(n, one, few, many)
+}
+
+// ruPlural выбирает форму слова для числа n: 1 день, 2 дня, 5 дней
+func ruPlural(n int, one, few, many string) string {
+	format := many
+	switch {
+	case n%10 == 1 && n%100 != 11:
+		format = one
+	case n%10 >= 2 && n%10 <= 4 && (n%100 < 12 || n%100 > 14):
+		format = few
+	}
+	return fmt.Sprintf(format, n)
+}
+
+// ruJoin перечисляет элементы через запятую, последний — через "и"
+func ruJoin(items []string) string {
+	if len(items) <= 1 {
+		return strings.Join(items, "")
+	}
+	return strings.Join(items[:len(items)-1], ", ") + " и " + items[len(items)-1]
+}
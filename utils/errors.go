@@ -0,0 +1,24 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Ошибки правил повторения. Функции пакета оборачивают их с подробностями,
+// поэтому вид ошибки проверяется через errors.Is.
+var (
+	ErrUnknownRule    = errors.New("неизвестный тип правила повторения")
+	ErrOutOfRange     = errors.New("значение вне допустимого диапазона")
+	ErrMalformedRule  = errors.New("неверный формат правила повторения")
+	ErrImpossibleRule = errors.New("правило повторения никогда не выполняется")
+	ErrRuleExhausted  = errors.New("правило повторения исчерпано")
+	// ErrOutsideCalendar — дата по правилу с рабочими днями выпала на год,
+	// за который в производственном календаре нет данных
+	ErrOutsideCalendar = errors.New("дата вне производственного календаря")
+)
+
+// ruleError дополняет ошибку правила повторения подробностями
+func ruleError(kind error, format string, args ...any) error {
+	return fmt.Errorf("%w: %s", kind, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,78 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// MonthRule описывает правило повторения "m <дни> [<месяцы>]"
+type MonthRule struct {
+	Days   []int        // дни месяца, -1 и -2 — последний и предпоследний день
+	Months []time.Month // месяцы, в которые повторяется задача; пусто — любой месяц
+}
+
+// ParseMonthRule разбирает часть правила после "m "
+func ParseMonthRule(spec string) (MonthRule, error) {
+	var rule MonthRule
+	parts := strings.Fields(spec)
+	if len(parts) == 0 || len(parts) > 2 {
+		return rule, ruleError(ErrMalformedRule, "ожидается \"m <дни> [<месяцы>]\"")
+	}
+
+	days, err := parseIntList(parts[0])
+	if err != nil {
+		return rule, err
+	}
+	for _, day := range days {
+		if day == 0 || day < -2 || day > 31 {
+			return rule, ruleError(ErrOutOfRange, "день месяца %d", day)
+		}
+	}
+	rule.Days = days
+
+	if len(parts) == 2 {
+		months, err := parseIntList(parts[1])
+		if err != nil {
+			return rule, err
+		}
+		for _, month := range months {
+			if month < 1 || month > 12 {
+				return rule, ruleError(ErrOutOfRange, "месяц %d", month)
+			}
+			rule.Months = append(rule.Months, time.Month(month))
+		}
+	}
+
+	return rule, nil
+}
+
+// Next возвращает первый подходящий под правило день после даты задачи и текущей даты
+func (r MonthRule) Next(now, date time.Time) (time.Time, error) {
+	return findNext(searchStart(now, date), r.Match)
+}
+
+// Match проверяет, попадает ли дата под правило
+func (r MonthRule) Match(t time.Time) bool {
+	if len(r.Months) > 0 && !containsMonth(r.Months, t.Month()) {
+		return false
+	}
+	lastDay := daysIn(t.Year(), t.Month())
+	for _, day := range r.Days {
+		if day < 0 {
+			day = lastDay + day + 1
+		}
+		if day == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func containsMonth(months []time.Month, month time.Month) bool {
+	for _, m := range months {
+		if m == month {
+			return true
+		}
+	}
+	return false
+}
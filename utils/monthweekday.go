@@ -0,0 +1,81 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MonthWeekdayRule описывает правило "mw <номер>:<день недели> [<месяцы>]" —
+// повторение в день недели с указанным номером в месяце, например во второй вторник.
+// Номер от 1 до 5, -1 — последний такой день месяца.
+type MonthWeekdayRule struct {
+	Weekdays []WeekdayNum
+	Months   []time.Month // месяцы, в которые повторяется задача; пусто — любой месяц
+}
+
+// ParseMonthWeekdayRule разбирает часть правила после "mw "
+func ParseMonthWeekdayRule(spec string) (MonthWeekdayRule, error) {
+	var rule MonthWeekdayRule
+	parts := strings.Fields(spec)
+	if len(parts) == 0 || len(parts) > 2 {
+		return rule, ruleError(ErrMalformedRule, "ожидается \"mw <номер>:<день недели> [<месяцы>]\"")
+	}
+
+	for _, item := range strings.Split(parts[0], ",") {
+		nStr, dayStr, ok := strings.Cut(item, ":")
+		if !ok {
+			return rule, ruleError(ErrMalformedRule, "ожидается \"<номер>:<день недели>\", получено %q", item)
+		}
+		n, err := strconv.Atoi(nStr)
+		if err != nil {
+			return rule, ruleError(ErrMalformedRule, "номер дня недели %q", nStr)
+		}
+		if n == 0 || n < -1 || n > 5 {
+			return rule, ruleError(ErrOutOfRange, "номер дня недели в месяце %d, допустимо от 1 до 5 или -1", n)
+		}
+		day, err := strconv.Atoi(dayStr)
+		if err != nil {
+			return rule, ruleError(ErrMalformedRule, "день недели %q", dayStr)
+		}
+		if day < 1 || day > 7 {
+			return rule, ruleError(ErrOutOfRange, "день недели %d", day)
+		}
+		rule.Weekdays = append(rule.Weekdays, WeekdayNum{N: n, Weekday: time.Weekday(day % 7)})
+	}
+
+	if len(parts) == 2 {
+		months, err := parseIntList(parts[1])
+		if err != nil {
+			return rule, err
+		}
+		for _, month := range months {
+			if month < 1 || month > 12 {
+				return rule, ruleError(ErrOutOfRange, "месяц %d", month)
+			}
+			rule.Months = append(rule.Months, time.Month(month))
+		}
+	}
+
+	return rule, nil
+}
+
+// Next возвращает первый подходящий под правило день после даты задачи и текущей даты
+func (r MonthWeekdayRule) Next(now, date time.Time) (time.Time, error) {
+	return findNext(searchStart(now, date), r.Match)
+}
+
+// Match проверяет, попадает ли дата под правило
+func (r MonthWeekdayRule) Match(t time.Time) bool {
+	if len(r.Months) > 0 && !containsMonth(r.Months, t.Month()) {
+		return false
+	}
+	fromStart := (t.Day()-1)/7 + 1
+	fromEnd := -((daysIn(t.Year(), t.Month())-t.Day())/7 + 1)
+	for _, day := range r.Weekdays {
+		if day.Weekday == t.Weekday() && (day.N == fromStart || day.N == fromEnd) {
+			return true
+		}
+	}
+	return false
+}
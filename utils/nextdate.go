@@ -1,36 +1,206 @@
 package utils
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 	"time"
 )
 
+// Rule — разобранное правило повторения
+type Rule interface {
+	// Next возвращает следующую дату выполнения, которая позже и now, и date
+	Next(now, date time.Time) (time.Time, error)
+}
+
+// DayRule описывает правило "d <число>" — повторение через указанное количество дней
+type DayRule struct {
+	Days int
+}
+
+// YearRule описывает правило "y" — ежегодное повторение
+type YearRule struct{}
+
+// probeDate — дата, от которой ValidateRepeat проверяет выполнимость правила
+var probeDate = time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// NextDate возвращает следующую дату выполнения задачи с датой date по правилу repeatStr.
+// Для исчерпанных правил (COUNT, UNTIL) возвращается ErrRuleExhausted.
 func NextDate(now time.Time, date time.Time, repeatStr string) (time.Time, error) {
-	var nextDate time.Time
-	// Обработка повторения по дням
-	if strings.HasPrefix(repeatStr, "d ") {
-		daysStr := strings.TrimPrefix(repeatStr, "d ")
-		days, err := strconv.Atoi(daysStr)
-		if err != nil || days < 0 || days > 400 {
-			return time.Time{}, nil // Вернуть пустое значение, если days недопустимо
+	rule, err := ParseRepeat(repeatStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return rule.Next(now, date)
+}
+
+// ParseRepeat разбирает правило повторения любого поддерживаемого вида
+func ParseRepeat(repeatStr string) (Rule, error) {
+	switch {
+	case repeatStr == "":
+		return nil, ruleError(ErrMalformedRule, "правило не указано")
+	case strings.HasPrefix(repeatStr, "RRULE:"):
+		// Правило в формате iCalendar (RFC 5545)
+		return ParseRRule(strings.TrimPrefix(repeatStr, "RRULE:"))
+	case IsCronRule(repeatStr):
+		// Cron-выражение с точностью до дня
+		return ParseCronRule(repeatStr)
+	}
+
+	kind, spec, _ := strings.Cut(repeatStr, " ")
+	switch kind {
+	case "d":
+		// Повторение по дням
+		return parseDayRule(spec)
+	case "y":
+		// Повторение по годам
+		if spec != "" {
+			return nil, ruleError(ErrMalformedRule, "ожидается \"y\"")
 		}
-		nextDate = date.AddDate(0, 0, days) // Добавляем дни
-		// Проверяем, чтобы следующая дата была больше текущей
-		for nextDate.Before(now) || nextDate.Equal(now) {
-			nextDate = nextDate.AddDate(0, 0, days)
+		return YearRule{}, nil
+	case "m":
+		// Повторение по дням месяца
+		return ParseMonthRule(spec)
+	case "w":
+		// Повторение по дням недели
+		return ParseWeekRule(spec)
+	case "b":
+		// Повторение через рабочие дни
+		return parseBusinessDayRule(spec)
+	case "bm":
+		// Повторение в рабочие дни месяца с указанными номерами
+		return ParseBusinessMonthRule(spec)
+	case "mw":
+		// Повторение в дни недели месяца с указанными номерами
+		return ParseMonthWeekdayRule(spec)
+	default:
+		return nil, ruleError(ErrUnknownRule, "%q", kind)
+	}
+}
+
+// ValidateRepeat проверяет, что правило повторения разбирается и когда-нибудь выполняется
+func ValidateRepeat(repeatStr string) error {
+	rule, err := ParseRepeat(repeatStr)
+	if err != nil {
+		return err
+	}
+	if _, err := rule.Next(probeDate, probeDate); errors.Is(err, ErrImpossibleRule) {
+		return err
+	}
+	return nil
+}
+
+func parseDayRule(spec string) (DayRule, error) {
+	days, err := strconv.Atoi(spec)
+	if err != nil {
+		return DayRule{}, ruleError(ErrMalformedRule, "ожидается \"d <число>\"")
+	}
+	if days < 1 || days > 400 {
+		return DayRule{}, ruleError(ErrOutOfRange, "интервал %d дней, допустимо от 1 до 400", days)
+	}
+	return DayRule{Days: days}, nil
+}
+
+// Next прибавляет дни к дате задачи, пока следующая дата не станет больше текущей
+func (r DayRule) Next(now, date time.Time) (time.Time, error) {
+	nextDate := date.AddDate(0, 0, r.Days)
+	for nextDate.Before(now) || nextDate.Equal(now) {
+		nextDate = nextDate.AddDate(0, 0, r.Days)
+	}
+	return nextDate, nil
+}
+
+// Next прибавляет годы к дате задачи, пока следующая дата не станет больше текущей
+func (r YearRule) Next(now, date time.Time) (time.Time, error) {
+	nextDate := date.AddDate(1, 0, 0)
+	for nextDate.Before(now) || nextDate.Equal(now) {
+		nextDate = nextDate.AddDate(1, 0, 0)
+	}
+	return nextDate, nil
+}
+
+// AdvanceRepeat возвращает правило повторения для задачи, перенесённой с date на next.
+// У RRULE с COUNT отсчёт ведётся от даты задачи, поэтому при переносе COUNT
+// уменьшается на количество пропущенных выполнений. Остальные правила не меняются.
+func AdvanceRepeat(repeatStr string, date, next time.Time) string {
+	if !strings.HasPrefix(repeatStr, "RRULE:") || next.IsZero() {
+		return repeatStr
+	}
+	spec := strings.TrimPrefix(repeatStr, "RRULE:")
+	rule, err := ParseRRule(spec)
+	if err != nil || rule.Count == 0 {
+		return repeatStr
+	}
+
+	remaining := rule.Count - rule.countBefore(date, truncateDay(next, date.Location()))
+	return withCount(spec, remaining)
+}
+
+// CountDown уменьшает COUNT правила RRULE на одно выполнение. Так считаются повторения
+// задач, которые отсчитываются от дня выполнения: день выполнения может не совпадать
+// с датой по правилу, но повторение всё равно израсходовано. Если это было последнее
+// повторение, возвращается false. Правила без COUNT не меняются.
+func CountDown(repeatStr string) (string, bool) {
+	if !strings.HasPrefix(repeatStr, "RRULE:") {
+		return repeatStr, true
+	}
+	spec := strings.TrimPrefix(repeatStr, "RRULE:")
+	rule, err := ParseRRule(spec)
+	if err != nil || rule.Count == 0 {
+		return repeatStr, true
+	}
+	if rule.Count == 1 {
+		return repeatStr, false
+	}
+	return withCount(spec, rule.Count-1), true
+}
+
+// withCount заменяет COUNT в правиле RRULE без префикса
+func withCount(spec string, count int) string {
+	parts := strings.Split(spec, ";")
+	for i, part := range parts {
+		if strings.HasPrefix(strings.ToUpper(part), "COUNT=") {
+			parts[i] = "COUNT=" + strconv.Itoa(count)
 		}
-	} else if strings.HasPrefix(repeatStr, "y") {
-		// Обработка повторения по годам
-		nextDate = date.AddDate(1, 0, 0) // Добавляем 1 год
-		// Проверяем, чтобы следующая дата была больше текущей
-		for nextDate.Before(now) || nextDate.Equal(now) {
-			nextDate = nextDate.AddDate(1, 0, 0)
+	}
+	return "RRULE:" + strings.Join(parts, ";")
+}
+
+// parseIntList разбирает список целых чисел через запятую
+func parseIntList(s string) ([]int, error) {
+	var values []int
+	for _, item := range strings.Split(s, ",") {
+		value, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, ruleError(ErrMalformedRule, "значение %q в списке", item)
 		}
-	} else {
-		return time.Time{}, nil // Вернуть пустое значение, если repeat недопустимо
+		values = append(values, value)
 	}
+	return values, nil
+}
 
-	return nextDate, nil
+// maxSearchDays ограничивает поиск подходящей даты, чтобы невыполнимые правила
+// (например, 31 февраля) не приводили к бесконечному циклу
+const maxSearchDays = 366 * 9
+
+// searchStart возвращает дату, после которой ищется следующее выполнение:
+// следующая дата должна быть позже и даты задачи, и текущей даты
+func searchStart(now, date time.Time) time.Time {
+	if now.After(date) {
+		return now
+	}
+	return date
+}
 
+// findNext возвращает первый день строго после start, подходящий под match,
+// или ErrImpossibleRule, если такого дня нет
+func findNext(start time.Time, match func(time.Time) bool) (time.Time, error) {
+	next := start
+	for i := 0; i < maxSearchDays; i++ {
+		next = next.AddDate(0, 0, 1)
+		if match(next) {
+			return next, nil
+		}
+	}
+	return time.Time{}, ruleError(ErrImpossibleRule, "нет подходящих дат в ближайшие %d лет", maxSearchDays/366)
 }
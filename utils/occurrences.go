@@ -0,0 +1,66 @@
+package utils
+
+import (
+	"errors"
+	"time"
+)
+
+// Occurrences возвращает не больше count следующих дат выполнения задачи с датой date
+// после now и не позже until (нулевое until — без ограничения).
+// Исчерпанное правило просто завершает список, как и выход за производственный
+// календарь после хотя бы одной найденной даты.
+func Occurrences(now, date time.Time, repeatStr string, count int, until time.Time) ([]time.Time, error) {
+	rule, err := ParseRepeat(repeatStr)
+	if err != nil {
+		return nil, err
+	}
+
+	dates := []time.Time{}
+	for len(dates) < count {
+		// Дата задачи не меняется, чтобы правила с COUNT отсчитывались от неё
+		next, err := rule.Next(now, date)
+		if errors.Is(err, ErrRuleExhausted) || (len(dates) > 0 && errors.Is(err, ErrOutsideCalendar)) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !until.IsZero() && next.After(until) {
+			break
+		}
+		dates = append(dates, next)
+		now = next
+	}
+	return dates, nil
+}
+
+// NextDateExcluding вычисляет следующую дату выполнения, пропуская даты,
+// для которых excluded возвращает true (отменённые повторения задачи)
+func NextDateExcluding(now, date time.Time, repeatStr string, excluded func(time.Time) bool) (time.Time, error) {
+	rule, err := ParseRepeat(repeatStr)
+	if err != nil {
+		return time.Time{}, err
+	}
+	for {
+		next, err := rule.Next(now, date)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if excluded == nil || !excluded(next) {
+			return next, nil
+		}
+		now = next
+	}
+}
+
+// IsOccurrence проверяет, выпадает ли на day повторение задачи с датой date
+func IsOccurrence(day, date time.Time, repeatStr string) bool {
+	if day.Equal(date) {
+		return true
+	}
+	if day.Before(date) {
+		return false
+	}
+	next, err := NextDate(day.AddDate(0, 0, -1), date, repeatStr)
+	return err == nil && next.Equal(day)
+}
@@ -0,0 +1,519 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ErrUnrecognizedPhrase возвращается, если фразу не удалось разобрать
+var ErrUnrecognizedPhrase = errors.New("не удалось распознать фразу")
+
+// Phrase — результат разбора фразы вроде "каждый понедельник" или "next friday"
+type Phrase struct {
+	Date   time.Time // дата задачи; если в фразе её нет — сегодня или ближайшая дата по правилу
+	Repeat string    // правило повторения в каноническом виде, пусто для одноразовой задачи
+}
+
+type unit int
+
+const (
+	unitDay unit = iota
+	unitWeek
+	unitMonth
+	unitYear
+)
+
+// Сокращения дней недели; полные названия распознаются по основе слова в phraseWeekday
+var phraseWeekdayShort = map[string]time.Weekday{
+	"пн": time.Monday, "вт": time.Tuesday, "ср": time.Wednesday, "чт": time.Thursday,
+	"пт": time.Friday, "сб": time.Saturday, "вс": time.Sunday,
+	"mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday, "thu": time.Thursday,
+	"fri": time.Friday, "sat": time.Saturday, "sun": time.Sunday,
+}
+
+var phraseWeekdayStems = []struct {
+	stem    string
+	weekday time.Weekday
+}{
+	{"понедельник", time.Monday}, {"вторник", time.Tuesday}, {"сред", time.Wednesday},
+	{"четверг", time.Thursday}, {"пятниц", time.Friday}, {"суббот", time.Saturday},
+	{"воскресен", time.Sunday},
+	{"monday", time.Monday}, {"tuesday", time.Tuesday}, {"wednesday", time.Wednesday},
+	{"thursday", time.Thursday}, {"friday", time.Friday}, {"saturday", time.Saturday},
+	{"sunday", time.Sunday},
+}
+
+var phraseUnits = map[string]unit{
+	"день": unitDay, "дня": unitDay, "дней": unitDay, "сутки": unitDay, "day": unitDay, "days": unitDay,
+	"неделю": unitWeek, "недели": unitWeek, "недель": unitWeek, "неделя": unitWeek, "week": unitWeek, "weeks": unitWeek,
+	"месяц": unitMonth, "месяца": unitMonth, "месяцев": unitMonth, "month": unitMonth, "months": unitMonth,
+	"год": unitYear, "года": unitYear, "лет": unitYear, "year": unitYear, "years": unitYear,
+}
+
+var phraseEvery = map[string]bool{
+	"каждый": true, "каждую": true, "каждое": true, "каждые": true, "каждого": true,
+	"every": true, "each": true,
+}
+
+var phraseNext = map[string]bool{
+	"следующий": true, "следующую": true, "следующее": true, "следующей": true, "next": true,
+}
+
+// Порядковые числительные в правилах вроде "второй вторник месяца", -1 — последний
+var phraseOrdinals = map[string]int{
+	"первый": 1, "первая": 1, "первое": 1, "первую": 1, "first": 1, "1st": 1,
+	"второй": 2, "вторая": 2, "второе": 2, "вторую": 2, "second": 2, "2nd": 2,
+	"третий": 3, "третья": 3, "третье": 3, "третью": 3, "third": 3, "3rd": 3,
+	"четвертый": 4, "четвертая": 4, "четвертое": 4, "четвертую": 4, "fourth": 4, "4th": 4,
+	"пятый": 5, "пятая": 5, "пятое": 5, "пятую": 5, "fifth": 5, "5th": 5,
+	"последний": -1, "последняя": -1, "последнее": -1, "последнюю": -1, "last": -1,
+}
+
+// Слова, которые не влияют на смысл фразы
+var phraseFillers = map[string]bool{
+	"в": true, "во": true, "на": true, "с": true, "со": true, "начиная": true, "и": true,
+	"on": true, "at": true, "the": true, "and": true, "from": true, "starting": true,
+}
+
+// phraseParser разбирает фразу по словам, накапливая дату и правило повторения
+type phraseParser struct {
+	words  []string
+	pos    int
+	today  time.Time
+	date   time.Time
+	repeat string
+	// fromToday — правило отсчитывается от сегодняшней даты, и без явной даты
+	// задача начинается сегодня
+	fromToday bool
+}
+
+// ParsePhrase разбирает русскую или английскую фразу ("каждый понедельник", "every 2 weeks",
+// "завтра", "next friday") в дату задачи и правило повторения относительно текущей даты now
+func ParsePhrase(now time.Time, text string) (Phrase, error) {
+	p := &phraseParser{
+		words: splitPhrase(text),
+		today: time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC),
+	}
+	if len(p.words) == 0 {
+		return Phrase{}, fmt.Errorf("%w: пустая фраза", ErrUnrecognizedPhrase)
+	}
+
+	for p.pos < len(p.words) {
+		matched, err := p.parseNext()
+		if err != nil {
+			return Phrase{}, err
+		}
+		if !matched {
+			return Phrase{}, fmt.Errorf("%w: «%s»", ErrUnrecognizedPhrase, p.words[p.pos])
+		}
+	}
+
+	phrase := Phrase{Date: p.date, Repeat: p.repeat}
+	if phrase.Date.IsZero() {
+		phrase.Date = p.today
+		if phrase.Repeat != "" && !p.fromToday {
+			// Без явной даты задача начинается с ближайшего подходящего под правило дня
+			rule, err := ParseRepeat(phrase.Repeat)
+			if err != nil {
+				return Phrase{}, err
+			}
+			switch rule.(type) {
+			case WeekRule, MonthRule, MonthWeekdayRule:
+				yesterday := p.today.AddDate(0, 0, -1)
+				next, err := rule.Next(yesterday, yesterday)
+				if err != nil {
+					return Phrase{}, err
+				}
+				phrase.Date = next
+			}
+		}
+	}
+	return phrase, nil
+}
+
+// splitPhrase приводит фразу к нижнему регистру и делит на слова,
+// отбрасывая знаки препинания, кроме точек и дефисов внутри дат
+func splitPhrase(text string) []string {
+	text = strings.ReplaceAll(strings.ToLower(text), "ё", "е")
+	var words []string
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '.' && r != '-'
+	}) {
+		if word = strings.Trim(word, ".-"); word != "" {
+			words = append(words, word)
+		}
+	}
+	return words
+}
+
+// parseNext пытается разобрать слова начиная с текущей позиции
+func (p *phraseParser) parseNext() (bool, error) {
+	word := p.words[p.pos]
+	switch word {
+	case "ежедневно", "daily":
+		p.pos++
+		return true, p.setRepeat("d 1")
+	case "еженедельно", "weekly":
+		p.pos++
+		return true, p.setRepeat("d 7")
+	case "ежемесячно", "monthly":
+		p.pos++
+		return true, p.setRepeat(p.intervalRepeat(unitMonth, 1))
+	case "ежегодно", "yearly", "annually":
+		p.pos++
+		return true, p.setRepeat("y")
+	case "сегодня", "today":
+		p.pos++
+		return true, p.setDate(p.today)
+	case "завтра", "tomorrow":
+		p.pos++
+		return true, p.setDate(p.today.AddDate(0, 0, 1))
+	case "послезавтра":
+		p.pos++
+		return true, p.setDate(p.today.AddDate(0, 0, 2))
+	}
+
+	if p.match("day", "after", "tomorrow") {
+		return true, p.setDate(p.today.AddDate(0, 0, 2))
+	}
+	if p.match("последний", "день", "месяца") || p.match("last", "day", "of", "month") {
+		return true, p.setRepeat("m -1")
+	}
+	if repeat, ok := p.ordinalWeekday(); ok {
+		return true, p.setRepeat(repeat)
+	}
+	if phraseEvery[word] {
+		p.pos++
+		matched, err := p.parseEvery()
+		if !matched {
+			p.pos--
+		}
+		return matched, err
+	}
+	if word == "по" || word == "on" {
+		// "по понедельникам", "on mondays" — повторение; "on monday" — дата
+		if weekdays, n := p.weekdayList(p.pos + 1); n > 0 && isPluralWeekday(p.words[p.pos+1]) {
+			p.pos += 1 + n
+			return true, p.setRepeat(weekRepeat(weekdays))
+		}
+	}
+	if word == "через" || word == "in" {
+		p.pos++
+		n, u, ok := p.amount()
+		if !ok {
+			p.pos--
+			return false, nil
+		}
+		return true, p.setDate(addUnits(p.today, u, n))
+	}
+	if phraseNext[word] && p.pos+1 < len(p.words) {
+		if weekday, ok := phraseWeekday(p.words[p.pos+1]); ok {
+			p.pos += 2
+			return true, p.setDate(nextWeekday(p.today.AddDate(0, 0, 1), weekday))
+		}
+		if u, ok := phraseUnits[p.words[p.pos+1]]; ok {
+			p.pos += 2
+			return true, p.setDate(addUnits(p.today, u, 1))
+		}
+	}
+	if weekday, ok := phraseWeekday(word); ok {
+		p.pos++
+		return true, p.setDate(nextWeekday(p.today, weekday))
+	}
+	if date, ok := parsePhraseDate(word, p.today); ok {
+		p.pos++
+		return true, p.setDate(date)
+	}
+	if phraseFillers[word] {
+		p.pos++
+		return true, nil
+	}
+	return false, nil
+}
+
+// parseEvery разбирает продолжение после "каждый"/"every"
+func (p *phraseParser) parseEvery() (bool, error) {
+	if p.pos >= len(p.words) {
+		return false, nil
+	}
+	if p.match("последний", "день", "месяца") || p.match("last", "day", "of", "month") {
+		return true, p.setRepeat("m -1")
+	}
+	if repeat, ok := p.ordinalWeekday(); ok {
+		return true, p.setRepeat(repeat)
+	}
+	if n, ok := p.businessDays(); ok {
+		return true, p.setRepeat("b " + strconv.Itoa(n))
+	}
+	if n, u, ok := p.amount(); ok {
+		return true, p.setRepeat(p.intervalRepeat(u, n))
+	}
+	if weekdays, n := p.weekdayList(p.pos); n > 0 {
+		p.pos += n
+		return true, p.setRepeat(weekRepeat(weekdays))
+	}
+	// "каждое 15 число", "every 15th"
+	word := p.words[p.pos]
+	day, err := strconv.Atoi(strings.TrimRight(word, "stndrh-егоя"))
+	if err == nil && day >= 1 && day <= 31 {
+		p.pos++
+		p.match("число")
+		p.match("числа")
+		p.match("day", "of", "month")
+		return true, p.setRepeat("m " + strconv.Itoa(day))
+	}
+	return false, nil
+}
+
+// match пропускает последовательность слов, если она начинается с текущей позиции;
+// артикли "the" между словами не учитываются
+func (p *phraseParser) match(words ...string) bool {
+	pos := p.pos
+	for _, word := range words {
+		for pos < len(p.words) && p.words[pos] == "the" {
+			pos++
+		}
+		if pos >= len(p.words) || p.words[pos] != word {
+			return false
+		}
+		pos++
+	}
+	p.pos = pos
+	return true
+}
+
+// amount разбирает "2 недели", "неделю", "a week" и возвращает количество и единицу
+func (p *phraseParser) amount() (int, unit, bool) {
+	if p.pos >= len(p.words) {
+		return 0, 0, false
+	}
+	if u, ok := phraseUnits[p.words[p.pos]]; ok {
+		p.pos++
+		return 1, u, true
+	}
+	if p.pos+1 >= len(p.words) {
+		return 0, 0, false
+	}
+	u, ok := phraseUnits[p.words[p.pos+1]]
+	if !ok {
+		return 0, 0, false
+	}
+	n := 1
+	if word := p.words[p.pos]; word != "a" && word != "an" && word != "one" {
+		var err error
+		n, err = strconv.Atoi(word)
+		if err != nil || n < 1 {
+			return 0, 0, false
+		}
+	}
+	p.pos += 2
+	return n, u, true
+}
+
+// businessDays разбирает "рабочий день", "3 рабочих дня", "2 business days"
+func (p *phraseParser) businessDays() (int, bool) {
+	pos, n := p.pos, 1
+	if pos < len(p.words) {
+		if value, err := strconv.Atoi(p.words[pos]); err == nil && value > 0 {
+			n = value
+			pos++
+		}
+	}
+	if pos+1 >= len(p.words) {
+		return 0, false
+	}
+	switch p.words[pos] {
+	case "рабочий", "рабочих", "working", "business":
+	default:
+		return 0, false
+	}
+	if u, ok := phraseUnits[p.words[pos+1]]; !ok || u != unitDay {
+		return 0, false
+	}
+	p.pos = pos + 2
+	return n, true
+}
+
+// ordinalWeekday разбирает "второй вторник месяца", "last friday of the quarter"
+// и возвращает правило "mw"; первый и последний месяцы квартала задаются списком месяцев
+func (p *phraseParser) ordinalWeekday() (string, bool) {
+	pos := p.pos
+	if pos+1 >= len(p.words) {
+		return "", false
+	}
+	n, ok := phraseOrdinals[p.words[pos]]
+	if !ok {
+		return "", false
+	}
+	weekday, ok := phraseWeekday(p.words[pos+1])
+	if !ok {
+		return "", false
+	}
+	p.pos += 2
+
+	p.match("of")
+	if !p.match("каждого") && !p.match("every") {
+		p.match("each")
+	}
+	var months string
+	switch {
+	case p.match("месяца") || p.match("month"):
+	case p.match("квартала") || p.match("quarter"):
+		months = " 1,4,7,10"
+		if n < 0 {
+			months = " 3,6,9,12"
+		}
+	default:
+		p.pos = pos
+		return "", false
+	}
+
+	day := int(weekday)
+	if weekday == time.Sunday {
+		day = 7
+	}
+	return "mw " + strconv.Itoa(n) + ":" + strconv.Itoa(day) + months, true
+}
+
+// weekdayList разбирает перечисление дней недели начиная с позиции pos
+// и возвращает дни и количество разобранных слов
+func (p *phraseParser) weekdayList(pos int) ([]time.Weekday, int) {
+	var weekdays []time.Weekday
+	i := pos
+	for i < len(p.words) {
+		weekday, ok := phraseWeekday(p.words[i])
+		if !ok {
+			break
+		}
+		weekdays = append(weekdays, weekday)
+		i++
+		if i+1 < len(p.words) && (p.words[i] == "и" || p.words[i] == "and") {
+			if _, ok := phraseWeekday(p.words[i+1]); ok {
+				i++
+			}
+		}
+	}
+	return weekdays, i - pos
+}
+
+// intervalRepeat строит правило для "каждые n единиц": дни и недели — "d", годы — "y",
+// месяцы — день месяца сегодняшней даты или RRULE с интервалом. После 28-го числа
+// задача повторяется в последний день месяца, чтобы не пропускать короткие месяцы.
+// Месячные правила отсчитываются от сегодняшней даты, с неё задача и начинается.
+func (p *phraseParser) intervalRepeat(u unit, n int) string {
+	switch u {
+	case unitWeek:
+		return "d " + strconv.Itoa(n*7)
+	case unitMonth:
+		p.fromToday = true
+		lastDay := p.today.Day() > 28
+		if n == 1 {
+			if lastDay {
+				return "m -1"
+			}
+			return "m " + strconv.Itoa(p.today.Day())
+		}
+		if lastDay {
+			return "RRULE:FREQ=MONTHLY;INTERVAL=" + strconv.Itoa(n) + ";BYMONTHDAY=-1"
+		}
+		return "RRULE:FREQ=MONTHLY;INTERVAL=" + strconv.Itoa(n)
+	case unitYear:
+		if n == 1 {
+			return "y"
+		}
+		return "RRULE:FREQ=YEARLY;INTERVAL=" + strconv.Itoa(n)
+	default:
+		return "d " + strconv.Itoa(n)
+	}
+}
+
+func (p *phraseParser) setDate(date time.Time) error {
+	if !p.date.IsZero() {
+		return fmt.Errorf("%w: дата указана несколько раз", ErrUnrecognizedPhrase)
+	}
+	p.date = date
+	return nil
+}
+
+func (p *phraseParser) setRepeat(repeat string) error {
+	if p.repeat != "" {
+		return fmt.Errorf("%w: правило повторения указано несколько раз", ErrUnrecognizedPhrase)
+	}
+	if err := ValidateRepeat(repeat); err != nil {
+		return err
+	}
+	p.repeat = repeat
+	return nil
+}
+
+// phraseWeekday распознаёт день недели в любом падеже и числе или его сокращение
+func phraseWeekday(word string) (time.Weekday, bool) {
+	if weekday, ok := phraseWeekdayShort[word]; ok {
+		return weekday, true
+	}
+	for _, item := range phraseWeekdayStems {
+		if strings.HasPrefix(word, item.stem) {
+			return item.weekday, true
+		}
+	}
+	return 0, false
+}
+
+// isPluralWeekday проверяет, что день недели стоит во множественном числе: "понедельникам", "mondays"
+func isPluralWeekday(word string) bool {
+	return strings.HasSuffix(word, "ам") || strings.HasSuffix(word, "ям") || strings.HasSuffix(word, "s")
+}
+
+func weekRepeat(weekdays []time.Weekday) string {
+	var days []string
+	for _, weekday := range weekdays {
+		day := int(weekday)
+		if weekday == time.Sunday {
+			day = 7
+		}
+		days = append(days, strconv.Itoa(day))
+	}
+	return "w " + strings.Join(days, ",")
+}
+
+// nextWeekday возвращает ближайший день недели weekday начиная с from включительно
+func nextWeekday(from time.Time, weekday time.Weekday) time.Time {
+	return from.AddDate(0, 0, (int(weekday)-int(from.Weekday())+7)%7)
+}
+
+func addUnits(date time.Time, u unit, n int) time.Time {
+	switch u {
+	case unitWeek:
+		return date.AddDate(0, 0, 7*n)
+	case unitMonth:
+		return date.AddDate(0, n, 0)
+	case unitYear:
+		return date.AddDate(n, 0, 0)
+	default:
+		return date.AddDate(0, 0, n)
+	}
+}
+
+// parsePhraseDate распознаёт даты в форматах DD.MM.YYYY, DD.MM, YYYY-MM-DD и YYYYMMDD.
+// Дата без года считается ближайшей будущей.
+func parsePhraseDate(word string, today time.Time) (time.Time, bool) {
+	for _, layout := range []string{"02.01.2006", "2.1.2006", "2006-01-02", "20060102"} {
+		if date, err := time.Parse(layout, word); err == nil {
+			return date, true
+		}
+	}
+	for _, layout := range []string{"02.01", "2.1"} {
+		if date, err := time.Parse(layout, word); err == nil {
+			date = date.AddDate(today.Year()-date.Year(), 0, 0)
+			if date.Before(today) {
+				date = date.AddDate(1, 0, 0)
+			}
+			return date, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,453 @@
+package utils
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Частоты повторения RRULE (RFC 5545)
+const (
+	FreqDaily   = "DAILY"
+	FreqWeekly  = "WEEKLY"
+	FreqMonthly = "MONTHLY"
+	FreqYearly  = "YEARLY"
+)
+
+var rruleWeekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+// WeekdayNum — элемент BYDAY: день недели с необязательным порядковым номером (2TU, -1FR)
+type WeekdayNum struct {
+	N       int // 0 — каждый такой день недели в периоде
+	Weekday time.Weekday
+}
+
+// RRule описывает правило повторения в формате iCalendar, например
+// "RRULE:FREQ=MONTHLY;BYDAY=-1FR". Правило вычисляется с точностью до дня.
+type RRule struct {
+	Freq       string
+	Interval   int
+	Count      int       // 0 — без ограничения
+	Until      time.Time // нулевое значение — без ограничения
+	ByDay      []WeekdayNum
+	ByMonthDay []int
+	ByMonth    []time.Month
+	BySetPos   []int
+	WeekStart  time.Weekday
+}
+
+// ParseRRule разбирает часть правила после "RRULE:"
+func ParseRRule(spec string) (RRule, error) {
+	rule := RRule{Interval: 1, WeekStart: time.Monday}
+	if spec == "" {
+		return rule, ruleError(ErrMalformedRule, "пустое правило RRULE")
+	}
+
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(spec, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return rule, ruleError(ErrMalformedRule, "элемент RRULE %q", part)
+		}
+		name = strings.ToUpper(name)
+		value = strings.ToUpper(value)
+		if seen[name] {
+			return rule, ruleError(ErrMalformedRule, "повторяющийся элемент RRULE %s", name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			switch value {
+			case FreqDaily, FreqWeekly, FreqMonthly, FreqYearly:
+				rule.Freq = value
+			default:
+				return rule, ruleError(ErrUnknownRule, "частота FREQ=%s", value)
+			}
+		case "INTERVAL":
+			rule.Interval, err = strconv.Atoi(value)
+			if err != nil || rule.Interval < 1 {
+				return rule, ruleError(ErrOutOfRange, "INTERVAL=%s", value)
+			}
+		case "COUNT":
+			rule.Count, err = strconv.Atoi(value)
+			if err != nil || rule.Count < 1 {
+				return rule, ruleError(ErrOutOfRange, "COUNT=%s", value)
+			}
+		case "UNTIL":
+			rule.Until, err = parseRRuleDate(value)
+			if err != nil {
+				return rule, err
+			}
+		case "BYDAY":
+			rule.ByDay, err = parseByDay(value)
+			if err != nil {
+				return rule, err
+			}
+		case "BYMONTHDAY":
+			rule.ByMonthDay, err = parseIntList(value)
+			if err != nil {
+				return rule, err
+			}
+			for _, day := range rule.ByMonthDay {
+				if day == 0 || day < -31 || day > 31 {
+					return rule, ruleError(ErrOutOfRange, "BYMONTHDAY=%d", day)
+				}
+			}
+		case "BYMONTH":
+			months, err := parseIntList(value)
+			if err != nil {
+				return rule, err
+			}
+			for _, month := range months {
+				if month < 1 || month > 12 {
+					return rule, ruleError(ErrOutOfRange, "BYMONTH=%d", month)
+				}
+				rule.ByMonth = append(rule.ByMonth, time.Month(month))
+			}
+		case "BYSETPOS":
+			rule.BySetPos, err = parseIntList(value)
+			if err != nil {
+				return rule, err
+			}
+			for _, pos := range rule.BySetPos {
+				if pos == 0 || pos < -366 || pos > 366 {
+					return rule, ruleError(ErrOutOfRange, "BYSETPOS=%d", pos)
+				}
+			}
+		case "WKST":
+			weekday, ok := rruleWeekdays[value]
+			if !ok {
+				return rule, ruleError(ErrMalformedRule, "WKST=%s", value)
+			}
+			rule.WeekStart = weekday
+		default:
+			return rule, ruleError(ErrUnknownRule, "элемент RRULE %s", name)
+		}
+	}
+
+	return rule, rule.validate()
+}
+
+// validate проверяет сочетания элементов, запрещённые RFC 5545
+func (r RRule) validate() error {
+	if r.Freq == "" {
+		return ruleError(ErrMalformedRule, "в RRULE не указан FREQ")
+	}
+	if r.Count > 0 && !r.Until.IsZero() {
+		return ruleError(ErrMalformedRule, "COUNT и UNTIL не могут использоваться вместе")
+	}
+	if r.Freq == FreqWeekly && len(r.ByMonthDay) > 0 {
+		return ruleError(ErrMalformedRule, "BYMONTHDAY не может использоваться с FREQ=WEEKLY")
+	}
+	for _, day := range r.ByDay {
+		if day.N == 0 {
+			continue
+		}
+		if r.Freq != FreqMonthly && r.Freq != FreqYearly {
+			return ruleError(ErrMalformedRule, "порядковый номер в BYDAY допустим только для FREQ=MONTHLY и FREQ=YEARLY")
+		}
+		if r.Freq == FreqMonthly || len(r.ByMonth) > 0 {
+			if day.N < -5 || day.N > 5 {
+				return ruleError(ErrOutOfRange, "порядковый номер %d в BYDAY", day.N)
+			}
+		}
+	}
+	if len(r.BySetPos) > 0 && len(r.ByDay) == 0 && len(r.ByMonthDay) == 0 && len(r.ByMonth) == 0 {
+		return ruleError(ErrMalformedRule, "BYSETPOS используется только вместе с другими BYxxx")
+	}
+	return nil
+}
+
+// parseRRuleDate разбирает дату UNTIL в формате YYYYMMDD или YYYYMMDDTHHMMSS[Z]
+func parseRRuleDate(value string) (time.Time, error) {
+	datePart, _, _ := strings.Cut(value, "T")
+	until, err := time.Parse("20060102", datePart)
+	if err != nil {
+		return time.Time{}, ruleError(ErrMalformedRule, "UNTIL=%s", value)
+	}
+	return until, nil
+}
+
+// parseByDay разбирает список BYDAY, например "MO,WE" или "2TU,-1FR"
+func parseByDay(value string) ([]WeekdayNum, error) {
+	var days []WeekdayNum
+	for _, item := range strings.Split(value, ",") {
+		if len(item) < 2 {
+			return nil, ruleError(ErrMalformedRule, "BYDAY=%s", item)
+		}
+		weekday, ok := rruleWeekdays[item[len(item)-2:]]
+		if !ok {
+			return nil, ruleError(ErrMalformedRule, "BYDAY=%s", item)
+		}
+		day := WeekdayNum{Weekday: weekday}
+		if num := item[:len(item)-2]; num != "" {
+			n, err := strconv.Atoi(num)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, ruleError(ErrMalformedRule, "BYDAY=%s", item)
+			}
+			day.N = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// Next возвращает первое выполнение правила после даты задачи и текущей даты.
+// Отсчёт выполнений (в том числе для COUNT) ведётся от даты задачи.
+func (r RRule) Next(now, date time.Time) (time.Time, error) {
+	return r.nextAfter(date, searchStart(now, date))
+}
+
+// nextAfter возвращает первое выполнение правила с началом dtstart строго после дня after.
+// Если выполнения закончились по COUNT или UNTIL, возвращается ErrRuleExhausted.
+func (r RRule) nextAfter(dtstart, after time.Time) (time.Time, error) {
+	dtstart = truncateDay(dtstart, dtstart.Location())
+	after = truncateDay(after, dtstart.Location())
+
+	var next time.Time
+	err := r.walk(dtstart, after.AddDate(0, 0, maxSearchDays), func(day time.Time, _ int) bool {
+		if day.After(after) {
+			next = day
+			return false
+		}
+		return true
+	})
+	return next, err
+}
+
+// countBefore возвращает количество выполнений правила от dtstart до дня before (не включая его)
+func (r RRule) countBefore(dtstart, before time.Time) int {
+	dtstart = truncateDay(dtstart, dtstart.Location())
+
+	count := 0
+	r.walk(dtstart, before, func(day time.Time, n int) bool {
+		if !day.Before(before) {
+			return false
+		}
+		count = n
+		return true
+	})
+	return count
+}
+
+// walk перебирает выполнения правила с началом dtstart по порядку и передаёт в visit день
+// и его номер, пока visit не вернёт false. Перебор прекращается с ErrRuleExhausted, когда
+// выполнения закончились по COUNT или UNTIL, и с ErrImpossibleRule после периода, начатого позже limit.
+func (r RRule) walk(dtstart, limit time.Time, visit func(day time.Time, n int) bool) error {
+	count := 0
+	for k := 0; ; k++ {
+		periodStart := r.periodStart(dtstart, k)
+		if periodStart.After(limit) {
+			return ruleError(ErrImpossibleRule, "нет подходящих дат в ближайшие %d лет", maxSearchDays/366)
+		}
+		if !r.Until.IsZero() && periodStart.After(r.Until) {
+			return ruleError(ErrRuleExhausted, "достигнута дата UNTIL")
+		}
+		for _, day := range r.expand(dtstart, periodStart) {
+			if day.Before(dtstart) {
+				continue
+			}
+			if !r.Until.IsZero() && day.After(r.Until) {
+				return ruleError(ErrRuleExhausted, "достигнута дата UNTIL")
+			}
+			count++
+			if r.Count > 0 && count > r.Count {
+				return ruleError(ErrRuleExhausted, "выполнено %d повторений из COUNT", r.Count)
+			}
+			if !visit(day, count) {
+				return nil
+			}
+		}
+	}
+}
+
+// periodStart возвращает начало k-го периода повторения, считая от dtstart
+func (r RRule) periodStart(dtstart time.Time, k int) time.Time {
+	step := k * r.Interval
+	switch r.Freq {
+	case FreqWeekly:
+		offset := (int(dtstart.Weekday()) - int(r.WeekStart) + 7) % 7
+		return dtstart.AddDate(0, 0, step*7-offset)
+	case FreqMonthly:
+		return time.Date(dtstart.Year(), dtstart.Month()+time.Month(step), 1, 0, 0, 0, 0, dtstart.Location())
+	case FreqYearly:
+		return time.Date(dtstart.Year()+step, time.January, 1, 0, 0, 0, 0, dtstart.Location())
+	default:
+		return dtstart.AddDate(0, 0, step)
+	}
+}
+
+// expand возвращает упорядоченный список дней периода, подходящих под правило
+func (r RRule) expand(dtstart, periodStart time.Time) []time.Time {
+	var days []time.Time
+	switch r.Freq {
+	case FreqDaily:
+		if r.matchMonth(periodStart) && r.matchMonthDay(periodStart) && r.matchWeekday(periodStart) {
+			days = append(days, periodStart)
+		}
+	case FreqWeekly:
+		for i := 0; i < 7; i++ {
+			day := periodStart.AddDate(0, 0, i)
+			if !r.matchMonth(day) {
+				continue
+			}
+			if len(r.ByDay) == 0 && day.Weekday() != dtstart.Weekday() {
+				continue
+			}
+			if len(r.ByDay) > 0 && !r.matchWeekday(day) {
+				continue
+			}
+			days = append(days, day)
+		}
+	case FreqMonthly:
+		if r.matchMonth(periodStart) {
+			days = r.monthDays(dtstart, periodStart.Year(), periodStart.Month())
+		}
+	case FreqYearly:
+		days = r.yearDays(dtstart, periodStart.Year())
+	}
+	return r.applySetPos(days)
+}
+
+// monthDays возвращает дни месяца, подходящие под BYMONTHDAY и BYDAY
+// (порядковые номера BYDAY считаются внутри месяца)
+func (r RRule) monthDays(dtstart time.Time, year int, month time.Month) []time.Time {
+	var days []time.Time
+	lastDay := daysIn(year, month)
+	for d := 1; d <= lastDay; d++ {
+		day := time.Date(year, month, d, 0, 0, 0, 0, dtstart.Location())
+		if len(r.ByMonthDay) == 0 && len(r.ByDay) == 0 {
+			if d == dtstart.Day() {
+				days = append(days, day)
+			}
+			continue
+		}
+		if len(r.ByMonthDay) > 0 && !r.matchMonthDay(day) {
+			continue
+		}
+		if len(r.ByDay) > 0 && !matchWeekdayNum(r.ByDay, day, (d-1)/7+1, (lastDay-d)/7+1) {
+			continue
+		}
+		days = append(days, day)
+	}
+	return days
+}
+
+// yearDays возвращает дни года, подходящие под правило с FREQ=YEARLY
+func (r RRule) yearDays(dtstart time.Time, year int) []time.Time {
+	var days []time.Time
+	switch {
+	case len(r.ByMonth) > 0:
+		months := append([]time.Month(nil), r.ByMonth...)
+		sort.Slice(months, func(i, j int) bool { return months[i] < months[j] })
+		for _, month := range months {
+			days = append(days, r.monthDays(dtstart, year, month)...)
+		}
+	case len(r.ByDay) > 0:
+		// Порядковые номера BYDAY без BYMONTH считаются внутри года
+		first := time.Date(year, time.January, 1, 0, 0, 0, 0, dtstart.Location())
+		total := 365
+		if daysIn(year, time.February) == 29 {
+			total = 366
+		}
+		for i := 0; i < total; i++ {
+			day := first.AddDate(0, 0, i)
+			if len(r.ByMonthDay) > 0 && !r.matchMonthDay(day) {
+				continue
+			}
+			if matchWeekdayNum(r.ByDay, day, i/7+1, (total-1-i)/7+1) {
+				days = append(days, day)
+			}
+		}
+	case len(r.ByMonthDay) > 0:
+		for month := time.January; month <= time.December; month++ {
+			days = append(days, r.monthDays(dtstart, year, month)...)
+		}
+	default:
+		if dtstart.Day() <= daysIn(year, dtstart.Month()) {
+			days = append(days, time.Date(year, dtstart.Month(), dtstart.Day(), 0, 0, 0, 0, dtstart.Location()))
+		}
+	}
+	return days
+}
+
+// applySetPos оставляет из списка дней только позиции, указанные в BYSETPOS
+func (r RRule) applySetPos(days []time.Time) []time.Time {
+	if len(r.BySetPos) == 0 || len(days) == 0 {
+		return days
+	}
+	selected := make(map[int]bool)
+	for _, pos := range r.BySetPos {
+		if pos > 0 && pos <= len(days) {
+			selected[pos-1] = true
+		} else if pos < 0 && -pos <= len(days) {
+			selected[len(days)+pos] = true
+		}
+	}
+	var result []time.Time
+	for i, day := range days {
+		if selected[i] {
+			result = append(result, day)
+		}
+	}
+	return result
+}
+
+func (r RRule) matchMonth(t time.Time) bool {
+	return len(r.ByMonth) == 0 || containsMonth(r.ByMonth, t.Month())
+}
+
+func (r RRule) matchMonthDay(t time.Time) bool {
+	if len(r.ByMonthDay) == 0 {
+		return true
+	}
+	lastDay := daysIn(t.Year(), t.Month())
+	for _, day := range r.ByMonthDay {
+		if day < 0 {
+			day = lastDay + day + 1
+		}
+		if day == t.Day() {
+			return true
+		}
+	}
+	return false
+}
+
+func (r RRule) matchWeekday(t time.Time) bool {
+	return len(r.ByDay) == 0 || matchWeekdayNum(r.ByDay, t, 0, 0)
+}
+
+// matchWeekdayNum проверяет день по списку BYDAY; fromStart и fromEnd —
+// номер этого дня недели в периоде, считая с начала и с конца
+func matchWeekdayNum(byDay []WeekdayNum, t time.Time, fromStart, fromEnd int) bool {
+	for _, day := range byDay {
+		if day.Weekday != t.Weekday() {
+			continue
+		}
+		if day.N == 0 || day.N == fromStart || day.N == -fromEnd {
+			return true
+		}
+	}
+	return false
+}
+
+// daysIn возвращает количество дней в месяце: нулевой день следующего месяца — последний день текущего
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+// truncateDay отбрасывает время суток, оставляя дату в указанной зоне
+func truncateDay(t time.Time, loc *time.Location) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, loc)
+}
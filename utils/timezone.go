@@ -0,0 +1,60 @@
+package utils
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	locationMu      sync.RWMutex
+	defaultLocation = time.Local
+)
+
+// DefaultLocation возвращает часовой пояс задач, для которых он не указан
+func DefaultLocation() *time.Location {
+	locationMu.RLock()
+	defer locationMu.RUnlock()
+	return defaultLocation
+}
+
+// SetDefaultLocation заменяет часовой пояс по умолчанию
+func SetDefaultLocation(loc *time.Location) {
+	locationMu.Lock()
+	defer locationMu.Unlock()
+	defaultLocation = loc
+}
+
+// LoadLocation возвращает часовой пояс IANA по имени, для пустого имени — пояс по умолчанию
+func LoadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return DefaultLocation(), nil
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("неизвестный часовой пояс: %s", name)
+	}
+	return loc, nil
+}
+
+// Today возвращает текущую дату в часовом поясе loc в виде полуночи UTC,
+// как и даты задач, разобранные из формата YYYYMMDD
+func Today(now time.Time, loc *time.Location) time.Time {
+	year, month, day := now.In(loc).Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// DueTime возвращает момент выполнения задачи с датой date и временем clock
+// в формате HH:MM в часовом поясе loc; без времени — начало дня
+func DueTime(date time.Time, clock string, loc *time.Location) (time.Time, error) {
+	var hour, minute int
+	if clock != "" {
+		t, err := time.Parse("15:04", clock)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("неверный формат времени: %s", clock)
+		}
+		hour, minute = t.Hour(), t.Minute()
+	}
+	year, month, day := date.Date()
+	return time.Date(year, month, day, hour, minute, 0, 0, loc), nil
+}
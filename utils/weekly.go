@@ -0,0 +1,49 @@
+package utils
+
+import (
+	"strings"
+	"time"
+)
+
+// WeekRule описывает правило повторения "w <дни недели>", где 1 — понедельник, 7 — воскресенье
+type WeekRule struct {
+	Weekdays []time.Weekday
+}
+
+// ParseWeekRule разбирает часть правила после "w "
+func ParseWeekRule(spec string) (WeekRule, error) {
+	var rule WeekRule
+	parts := strings.Fields(spec)
+	if len(parts) != 1 {
+		return rule, ruleError(ErrMalformedRule, "ожидается \"w <дни недели>\"")
+	}
+
+	days, err := parseIntList(parts[0])
+	if err != nil {
+		return rule, err
+	}
+	for _, day := range days {
+		if day < 1 || day > 7 {
+			return rule, ruleError(ErrOutOfRange, "день недели %d", day)
+		}
+		// В пакете time воскресенье — нулевой день недели
+		rule.Weekdays = append(rule.Weekdays, time.Weekday(day%7))
+	}
+
+	return rule, nil
+}
+
+// Next возвращает первый подходящий под правило день после даты задачи и текущей даты
+func (r WeekRule) Next(now, date time.Time) (time.Time, error) {
+	return findNext(searchStart(now, date), r.Match)
+}
+
+// Match проверяет, попадает ли дата под правило
+func (r WeekRule) Match(t time.Time) bool {
+	for _, weekday := range r.Weekdays {
+		if weekday == t.Weekday() {
+			return true
+		}
+	}
+	return false
+}